@@ -0,0 +1,182 @@
+package grpcdump
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/txtar"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	protocolFile   = "protocol"
+	authorityFile  = "authority"
+	deadlineFile   = "deadline"
+	compressorFile = "compressor"
+	sizeFile       = "size"
+)
+
+// callInfo is the comparable representation of the call options.
+type callInfo struct {
+	Protocol   string
+	Authority  string
+	Deadline   time.Duration
+	Compressor string
+	Sizes      []int
+}
+
+func newCallInfo(g *GRPC, msgs []Message) callInfo {
+	// The sizes are missing for snapshots created before the sizes are
+	// recorded.
+	var sizes []int
+	if slices.ContainsFunc(msgs, func(msg Message) bool { return msg.Size > 0 }) {
+		for _, msg := range msgs {
+			sizes = append(sizes, msg.Size)
+		}
+	}
+
+	return callInfo{
+		Protocol:   g.Protocol,
+		Authority:  g.Authority,
+		Deadline:   g.Deadline,
+		Compressor: g.Compressor,
+		Sizes:      sizes,
+	}
+}
+
+// optional clears the fields whose sections are missing from the snapshot,
+// so that snapshots created before the fields are recorded still matches.
+// The sections of the zero values are omitted too, so only the sections
+// missing from the snapshot, see Read, are cleared.
+func (c callInfo) optional(missing []string) callInfo {
+	for _, name := range missing {
+		switch name {
+		case protocolFile:
+			c.Protocol = ""
+		case authorityFile:
+			c.Authority = ""
+		case deadlineFile:
+			c.Deadline = 0
+		case compressorFile:
+			c.Compressor = ""
+		case sizeFile:
+			c.Sizes = nil
+		}
+	}
+
+	return c
+}
+
+// missingCallInfo returns the call sections missing from snapshots created
+// before the sections are recorded.
+// The protocol is always recorded, so its section is only missing from older
+// snapshots. The other sections are recorded together, and the authority is
+// always recorded, so they are missing when none of them are present.
+func missingCallInfo(seen map[string]bool) []string {
+	var missing []string
+	if !seen[protocolFile] {
+		missing = append(missing, protocolFile)
+	}
+
+	sections := []string{authorityFile, deadlineFile, compressorFile, sizeFile}
+	if !slices.ContainsFunc(sections, func(name string) bool { return seen[name] }) {
+		missing = append(missing, sections...)
+	}
+
+	return missing
+}
+
+// deadlineFromContext returns the time left before the deadline, rounded to
+// 100ms so that the latency does not change the snapshot.
+func deadlineFromContext(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+
+	d := time.Until(deadline)
+	if r := d.Round(100 * time.Millisecond); r > 0 {
+		return r
+	}
+
+	return max(d.Round(time.Millisecond), time.Millisecond)
+}
+
+// authorityFromIncomingContext returns the `:authority` pseudo header received
+// by the server.
+func authorityFromIncomingContext(ctx context.Context) string {
+	if vs := metadata.ValueFromIncomingContext(ctx, ":authority"); len(vs) > 0 {
+		return vs[0]
+	}
+
+	return ""
+}
+
+// authorityFromTarget returns the authority from the dial target, e.g.
+// `dns:///example.com:443`.
+func authorityFromTarget(target string) string {
+	if _, after, ok := strings.Cut(target, ":///"); ok {
+		return after
+	}
+
+	return target
+}
+
+// compressorFromCallOptions returns the compressor set by grpc.UseCompressor.
+func compressorFromCallOptions(opts []grpc.CallOption) string {
+	var name string
+	for _, opt := range opts {
+		if c, ok := opt.(grpc.CompressorCallOption); ok {
+			name = c.CompressorType
+		}
+	}
+
+	return name
+}
+
+func writeCallInfo(g *GRPC) []txtar.File {
+	var deadline string
+	if g.Deadline > 0 {
+		deadline = g.Deadline.String()
+	}
+
+	var sizes []string
+	for _, msg := range g.Messages {
+		sizes = append(sizes, fmt.Sprintf("%s: %d", messageFile(g, msg), msg.Size))
+	}
+
+	return []txtar.File{
+		{Name: protocolFile, Data: appendNewLine([]byte(g.Protocol))},
+		{Name: authorityFile, Data: appendNewLine([]byte(g.Authority))},
+		{Name: deadlineFile, Data: appendNewLine([]byte(deadline))},
+		{Name: compressorFile, Data: appendNewLine([]byte(g.Compressor))},
+		{Name: sizeFile, Data: appendNewLine([]byte(strings.Join(sizes, "\n")))},
+	}
+}
+
+func readSizes(b []byte) ([]int, error) {
+	var sizes []int
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		i := strings.LastIndex(line, ": ")
+		if i == -1 {
+			return nil, fmt.Errorf("grpcdump: invalid size: %s", line)
+		}
+
+		n, err := strconv.Atoi(line[i+2:])
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, n)
+	}
+
+	return sizes, nil
+}
@@ -0,0 +1,119 @@
+package grpcdump
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// StreamClientInterceptor is a function that intercepts outgoing streaming RPCs on the client.
+// If the server is not intercepted, e.g. third-party services, the call is recorded from the client side when the stream ends.
+// Calls that are not recorded are passed through.
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	md, id, ok := testIDFromOutgoingContext(ctx)
+	if !ok {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	w := &clientStreamInterceptor{
+		id:   id,
+		md:   md,
+		desc: desc,
+		g: &GRPC{
+			FullMethod:     method,
+			Metadata:       md,
+			IsServerStream: desc.ServerStreams,
+			IsClientStream: desc.ClientStreams,
+			Protocol:       ProtocolGRPC,
+			Authority:      authorityFromTarget(cc.Target()),
+			Deadline:       deadlineFromContext(ctx),
+			Compressor:     compressorFromCallOptions(opts),
+		},
+	}
+	opts = append(opts, grpc.Peer(&w.peer))
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		w.done(err)
+		return nil, err
+	}
+	w.ClientStream = stream
+
+	return w, nil
+}
+
+type clientStreamInterceptor struct {
+	grpc.ClientStream
+	id   string
+	md   metadata.MD
+	desc *grpc.StreamDesc
+	peer peer.Peer
+	once sync.Once
+
+	mu sync.Mutex
+	g  *GRPC
+}
+
+func (s *clientStreamInterceptor) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		s.g.Messages = append(s.g.Messages, origin(OriginClient, m))
+		s.mu.Unlock()
+	}
+
+	return err
+}
+
+func (s *clientStreamInterceptor) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		s.g.Messages = append(s.g.Messages, origin(OriginServer, m))
+		s.mu.Unlock()
+
+		// The stream ends after the only response.
+		if !s.desc.ServerStreams {
+			s.done(nil)
+		}
+
+		return nil
+	}
+
+	if errors.Is(err, io.EOF) {
+		s.done(nil)
+	} else {
+		s.done(err)
+	}
+
+	return err
+}
+
+// done records the call, unless it is already recorded by the server.
+func (s *clientStreamInterceptor) done(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		g := s.g
+		g.Addr = addrFromPeer(&s.peer)
+		g.Status = newStatus(err)
+		if s.ClientStream != nil {
+			g.Header, _ = s.ClientStream.Header()
+			g.Trailer = clientTrailer(s.ClientStream.Trailer())
+		}
+		s.mu.Unlock()
+
+		mu.Lock()
+		if sg, ok := testIds[s.id]; ok {
+			// The compressor is only known to the client.
+			sg.Compressor = g.Compressor
+		} else {
+			testIds[s.id] = g
+		}
+		mu.Unlock()
+	})
+}
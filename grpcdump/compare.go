@@ -1,24 +1,33 @@
 package grpcdump
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 
+	"github.com/alextanhongpin/testdump/grpcdump/internal"
 	"github.com/alextanhongpin/testdump/pkg/diff"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 // CompareOption is a struct that holds comparison options for different parts of a gRPC message.
-// It includes options for comparing the Message, Metadata, Trailer, and Header.
+// It includes options for comparing the Message, Status, Metadata, Trailer, and Header.
 type CompareOption struct {
 	Message  []cmp.Option // Options for comparing the message part of a gRPC message.
+	Status   []cmp.Option // Options for comparing the status part of a gRPC message.
 	Metadata []cmp.Option // Options for comparing the metadata part of a gRPC message.
 	Trailer  []cmp.Option // Options for comparing the trailer part of a gRPC message.
 	Header   []cmp.Option // Options for comparing the header part of a gRPC message.
+	Call     []cmp.Option // Options for comparing the authority, deadline, compressor and message sizes.
 }
 
 type comparer struct {
 	colors bool
 	opt    CompareOption
+	order  messageOrder
 }
 
 func (c *comparer) Compare(a, b any) error {
@@ -48,11 +57,15 @@ func (c *comparer) compare(snapshot, received *GRPC) error {
 		return fmt.Errorf("Full Method: %w", err)
 	}
 
-	if err := comparer(x.Messages, y.Messages, opt.Message...); err != nil {
+	xMsgs, yMsgs := c.order.sort(x.Messages), c.order.sort(y.Messages)
+
+	// The sizes are compared separately, together with the call options.
+	msgOpts := append([]cmp.Option{cmpopts.IgnoreFields(Message{}, "Size")}, opt.Message...)
+	if err := comparer(xMsgs, yMsgs, msgOpts...); err != nil {
 		return fmt.Errorf("Message: %w", err)
 	}
 
-	if err := comparer(x.Status, y.Status); err != nil {
+	if err := comparer(x.Status, y.Status, opt.Status...); err != nil {
 		return fmt.Errorf("Status: %w", err)
 	}
 
@@ -68,5 +81,107 @@ func (c *comparer) compare(snapshot, received *GRPC) error {
 		return fmt.Errorf("Trailer: %w", err)
 	}
 
+	xCall := newCallInfo(x, xMsgs)
+	yCall := newCallInfo(y, yMsgs).optional(x.missing)
+	if err := comparer(xCall, yCall, opt.Call...); err != nil {
+		return fmt.Errorf("Call: %w", err)
+	}
+
 	return nil
 }
+
+// messageOrder controls how the messages are ordered before comparison.
+type messageOrder struct {
+	// ignoreInterleaving groups the messages by origin, keeping the order of
+	// the messages from the same origin.
+	ignoreInterleaving bool
+
+	// unordered sorts the messages from the same origin, so that they are
+	// compared as a multiset.
+	unordered bool
+
+	// sortPaths are the paths of the values to sort the unordered messages
+	// by, before falling back to the whole message.
+	sortPaths []string
+}
+
+func (o messageOrder) sort(msgs []Message) []Message {
+	if !o.ignoreInterleaving && !o.unordered {
+		return msgs
+	}
+
+	type keyed struct {
+		msg  Message
+		keys []string
+	}
+
+	res := make([]keyed, len(msgs))
+	for i, msg := range msgs {
+		res[i].msg = msg
+		if o.unordered {
+			res[i].keys = o.keys(msg)
+		}
+	}
+
+	slices.SortStableFunc(res, func(a, b keyed) int {
+		// The client messages comes first.
+		if n := strings.Compare(a.msg.Origin, b.msg.Origin); n != 0 {
+			return n
+		}
+
+		return slices.Compare(a.keys, b.keys)
+	})
+
+	sorted := make([]Message, len(res))
+	for i, r := range res {
+		sorted[i] = r.msg
+	}
+
+	return sorted
+}
+
+// keys returns the JSON encoded values at the sort paths, followed by the
+// whole message.
+func (o messageOrder) keys(msg Message) []string {
+	vals := internal.LoadMapValues(msg.Message, o.sortPaths...)
+
+	keys := make([]string, 0, len(o.sortPaths)+1)
+	for _, path := range o.sortPaths {
+		keys = append(keys, jsonString(vals[path]))
+	}
+
+	return append(keys, jsonString(msg.Message))
+}
+
+func jsonString(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+var messageType = reflect.TypeOf(Message{})
+
+// filterMessages applies the option only to the messages that matches the
+// predicate.
+func filterMessages(pred func(int, Message) bool, opt cmp.Option) cmp.Option {
+	return cmp.FilterPath(func(p cmp.Path) bool {
+		for _, s := range p {
+			si, ok := s.(cmp.SliceIndex)
+			if !ok || si.Type() != messageType {
+				continue
+			}
+
+			i, j := si.SplitKeys()
+			vx, vy := si.Values()
+			if i < 0 {
+				i, vx = j, vy
+			}
+			if !vx.IsValid() {
+				return false
+			}
+
+			return pred(i, vx.Interface().(Message))
+		}
+
+		return false
+	}, opt)
+}
@@ -0,0 +1,403 @@
+package grpcdump
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ProtocolGRPC is the protocol of the calls recorded by the grpc-go
+// interceptors and proxy.
+// The protocols of the calls recorded by the connect interceptor are
+// connect.ProtocolConnect, connect.ProtocolGRPC and connect.ProtocolGRPCWeb.
+const ProtocolGRPC = connect.ProtocolGRPC
+
+// connectHeaders are the transport headers that are not recorded, since they
+// depends on the protocol and the compressors registered, not the call.
+// The compressor and the deadline are recorded separately.
+var connectHeaders = []string{
+	"accept-encoding",
+	"connect-accept-encoding",
+	"connect-content-encoding",
+	"connect-timeout-ms",
+	"content-encoding",
+	"content-length",
+	"grpc-accept-encoding",
+	"grpc-encoding",
+	"grpc-timeout",
+	"te",
+}
+
+// ConnectInterceptor is a function that returns a connect.Interceptor.
+// The interceptor records the calls made with the context from NewRecorder
+// into the same snapshot as the grpc-go interceptors, together with the
+// protocol, either connect, grpc or grpcweb.
+// Add it to both the handler and the client, so that the client forwards the
+// test-id in the request header. If the handler is not intercepted, e.g.
+// third-party services, the call is recorded from the client side.
+func ConnectInterceptor() connect.Interceptor {
+	return &connectInterceptor{}
+}
+
+type connectInterceptor struct{}
+
+func (i *connectInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return i.unaryClient(ctx, req, next)
+		}
+
+		return i.unaryHandler(ctx, req, next)
+	}
+}
+
+func (i *connectInterceptor) unaryHandler(ctx context.Context, req connect.AnyRequest, next connect.UnaryFunc) (connect.AnyResponse, error) {
+	md, id, ok := testIDFromHeader(req.Header())
+	if !ok {
+		// Pass through calls that are not recorded.
+		return next(ctx, req)
+	}
+
+	deadline := deadlineFromContext(ctx)
+	res, err := next(ctx, req)
+
+	g := newConnectGRPC(req.Spec(), req.Peer(), md)
+	g.Authority = req.Header().Get("Host")
+	g.Deadline = deadline
+	g.Compressor = compressorFromHeader(req.Header())
+	g.Messages = append(g.Messages, origin(OriginClient, req.Any()))
+	g.Status = newConnectStatus(err)
+	if err == nil {
+		g.Messages = append(g.Messages, origin(OriginServer, res.Any()))
+		g.Header = metadataFromHeader(res.Header())
+		g.Trailer = metadataFromHeader(res.Trailer())
+	}
+
+	mu.Lock()
+	testIds[id] = g
+	mu.Unlock()
+
+	return res, err
+}
+
+func (i *connectInterceptor) unaryClient(ctx context.Context, req connect.AnyRequest, next connect.UnaryFunc) (connect.AnyResponse, error) {
+	_, id, ok := testIDFromOutgoingContext(ctx)
+	if !ok {
+		return next(ctx, req)
+	}
+
+	// Record the headers set by the caller, before the test-id is added.
+	md := metadataFromHeader(req.Header())
+	req.Header().Set(grpcdumpTestID, id)
+
+	deadline := deadlineFromContext(ctx)
+	res, err := next(ctx, req)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The call is already recorded by the handler.
+	if _, ok := testIds[id]; ok {
+		return res, err
+	}
+
+	g := newConnectGRPC(req.Spec(), req.Peer(), md)
+	g.Authority = authorityFromHeader(req.Header(), req.Peer())
+	g.Deadline = deadline
+	g.Compressor = compressorFromHeader(req.Header())
+	g.Messages = append(g.Messages, origin(OriginClient, req.Any()))
+	g.Status = newConnectStatus(err)
+	if err == nil {
+		g.Messages = append(g.Messages, origin(OriginServer, res.Any()))
+		g.Header = metadataFromHeader(res.Header())
+		g.Trailer = metadataFromHeader(res.Trailer())
+	}
+	testIds[id] = g
+
+	return res, err
+}
+
+func (i *connectInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+
+		_, id, ok := testIDFromOutgoingContext(ctx)
+		if !ok {
+			return conn
+		}
+
+		// The request header is sent together with the first message, so the
+		// headers set by the caller are only known then.
+		conn.RequestHeader().Set(grpcdumpTestID, id)
+
+		return &connectClientConn{
+			StreamingClientConn: conn,
+			id:                  id,
+			g:                   newConnectGRPC(spec, conn.Peer(), nil),
+			deadline:            deadlineFromContext(ctx),
+		}
+	}
+}
+
+func (i *connectInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		md, id, ok := testIDFromHeader(conn.RequestHeader())
+		if !ok {
+			// Pass through calls that are not recorded.
+			return next(ctx, conn)
+		}
+
+		w := &connectHandlerConn{
+			StreamingHandlerConn: conn,
+			g:                    newConnectGRPC(conn.Spec(), conn.Peer(), md),
+		}
+		w.g.Authority = conn.RequestHeader().Get("Host")
+		w.g.Deadline = deadlineFromContext(ctx)
+		w.g.Compressor = compressorFromHeader(conn.RequestHeader())
+
+		err := next(ctx, w)
+
+		w.mu.Lock()
+		g := w.g
+		g.Status = newConnectStatus(err)
+		g.Header = metadataFromHeader(conn.ResponseHeader())
+		g.Trailer = metadataFromHeader(conn.ResponseTrailer())
+		w.mu.Unlock()
+
+		mu.Lock()
+		testIds[id] = g
+		mu.Unlock()
+
+		return err
+	}
+}
+
+type connectHandlerConn struct {
+	connect.StreamingHandlerConn
+
+	mu sync.Mutex
+	g  *GRPC
+}
+
+func (c *connectHandlerConn) Receive(m any) error {
+	err := c.StreamingHandlerConn.Receive(m)
+	if err == nil {
+		c.mu.Lock()
+		c.g.Messages = append(c.g.Messages, origin(OriginClient, m))
+		c.mu.Unlock()
+	}
+
+	return err
+}
+
+func (c *connectHandlerConn) Send(m any) error {
+	err := c.StreamingHandlerConn.Send(m)
+	if err == nil {
+		c.mu.Lock()
+		c.g.Messages = append(c.g.Messages, origin(OriginServer, m))
+		c.mu.Unlock()
+	}
+
+	return err
+}
+
+type connectClientConn struct {
+	connect.StreamingClientConn
+	id       string
+	deadline time.Duration
+	once     sync.Once
+
+	mu sync.Mutex
+	g  *GRPC
+}
+
+func (c *connectClientConn) Send(m any) error {
+	err := c.StreamingClientConn.Send(m)
+	if err == nil {
+		c.mu.Lock()
+		c.g.Messages = append(c.g.Messages, origin(OriginClient, m))
+		c.mu.Unlock()
+	}
+
+	return err
+}
+
+func (c *connectClientConn) Receive(m any) error {
+	err := c.StreamingClientConn.Receive(m)
+	if err == nil {
+		c.mu.Lock()
+		c.g.Messages = append(c.g.Messages, origin(OriginServer, m))
+		c.mu.Unlock()
+
+		return nil
+	}
+
+	if errors.Is(err, io.EOF) {
+		c.done(nil)
+	} else {
+		c.done(err)
+	}
+
+	return err
+}
+
+// CloseResponse records the call, in case the stream is closed before the
+// end.
+func (c *connectClientConn) CloseResponse() error {
+	err := c.StreamingClientConn.CloseResponse()
+	c.done(nil)
+
+	return err
+}
+
+// done records the call, unless it is already recorded by the handler.
+func (c *connectClientConn) done(err error) {
+	c.once.Do(func() {
+		c.mu.Lock()
+		g := c.g
+		g.Authority = authorityFromHeader(c.RequestHeader(), c.Peer())
+		g.Deadline = c.deadline
+		g.Compressor = compressorFromHeader(c.RequestHeader())
+		g.Status = newConnectStatus(err)
+		g.Metadata = metadataFromHeader(c.RequestHeader())
+		g.Metadata.Delete(grpcdumpTestID)
+		g.Header = metadataFromHeader(c.ResponseHeader())
+		g.Trailer = metadataFromHeader(c.ResponseTrailer())
+		c.mu.Unlock()
+
+		mu.Lock()
+		if _, ok := testIds[c.id]; !ok {
+			testIds[c.id] = g
+		}
+		mu.Unlock()
+	})
+}
+
+func newConnectGRPC(spec connect.Spec, pr connect.Peer, md metadata.MD) *GRPC {
+	return &GRPC{
+		Addr:           addrFromHostPort(pr.Addr),
+		FullMethod:     spec.Procedure,
+		Metadata:       md,
+		IsClientStream: spec.StreamType&connect.StreamTypeClient != 0,
+		IsServerStream: spec.StreamType&connect.StreamTypeServer != 0,
+		Protocol:       pr.Protocol,
+	}
+}
+
+// testIDFromHeader returns the request header without the test-id, and the
+// test-id.
+func testIDFromHeader(h http.Header) (metadata.MD, string, bool) {
+	id := h.Get(grpcdumpTestID)
+	if id == "" {
+		return nil, "", false
+	}
+
+	md := metadataFromHeader(h)
+	md.Delete(grpcdumpTestID)
+
+	// The host is recorded as the authority.
+	md.Delete("host")
+
+	return md, id, true
+}
+
+// metadataFromHeader converts the header into metadata, with the binary
+// values decoded, so that they are written the same way as the grpc-go
+// metadata.
+// The transport headers are skipped.
+func metadataFromHeader(h http.Header) metadata.MD {
+	md := make(metadata.MD, len(h))
+	for k, vs := range h {
+		k = strings.ToLower(k)
+		if slices.Contains(connectHeaders, k) {
+			continue
+		}
+
+		for _, v := range vs {
+			if strings.HasSuffix(k, "-bin") {
+				if b, err := connect.DecodeBinaryHeader(v); err == nil {
+					v = string(b)
+				}
+			}
+			md.Append(k, v)
+		}
+	}
+	if len(md) == 0 {
+		return nil
+	}
+
+	return md
+}
+
+// compressorFromHeader returns the compressor of the request for each
+// protocol.
+func compressorFromHeader(h http.Header) string {
+	for _, k := range []string{"Grpc-Encoding", "Connect-Content-Encoding", "Content-Encoding"} {
+		if v := h.Get(k); v != "" && v != "identity" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// authorityFromHeader returns the authority of the outgoing request. The
+// Host header is usually set by the transport, so the address of the peer,
+// which is the host of the URL on the client, is used instead.
+func authorityFromHeader(h http.Header, pr connect.Peer) string {
+	if v := h.Get("Host"); v != "" {
+		return v
+	}
+
+	return pr.Addr
+}
+
+// addrFromHostPort returns the host, without the port, similar to
+// addrFromPeer.
+func addrFromHostPort(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
+
+// newConnectStatus converts the connect error into a gRPC status, since the
+// codes are the same for all protocols.
+func newConnectStatus(err error) *Status {
+	if err == nil {
+		return newStatus(nil)
+	}
+
+	var cerr *connect.Error
+	if !errors.As(err, &cerr) {
+		return newStatus(status.Error(codes.Unknown, err.Error()))
+	}
+
+	sts := &spb.Status{
+		Code:    int32(cerr.Code()),
+		Message: cerr.Message(),
+	}
+	for _, d := range cerr.Details() {
+		sts.Details = append(sts.Details, &anypb.Any{
+			TypeUrl: "type.googleapis.com/" + d.Type(),
+			Value:   d.Bytes(),
+		})
+	}
+
+	return newStatus(status.ErrorProto(sts))
+}
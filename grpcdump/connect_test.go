@@ -0,0 +1,189 @@
+package grpcdump_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/alextanhongpin/testdump/grpcdump"
+	pb "github.com/alextanhongpin/testdump/grpcdump/testdata/helloworld/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+const (
+	sayHelloProcedure      = "/helloworld.v1.GreeterService/SayHello"
+	listGreetingsProcedure = "/helloworld.v1.GreeterService/ListGreetings"
+)
+
+func TestConnect(t *testing.T) {
+	ctx := context.Background()
+	ts := newConnectServer(t, connect.WithInterceptors(grpcdump.ConnectInterceptor()))
+
+	protocols := []struct {
+		name string
+		opts []connect.ClientOption
+	}{
+		{name: "connect"},
+		{name: "grpc", opts: []connect.ClientOption{connect.WithGRPC()}},
+		{name: "grpcweb", opts: []connect.ClientOption{connect.WithGRPCWeb()}},
+	}
+
+	dumpOpts := []grpcdump.Option{
+		grpcdump.IgnoreMetadata("user-agent", "x-user-agent"),
+		// The port of the test server is random.
+		grpcdump.IgnoreAuthority(),
+	}
+
+	for _, p := range protocols {
+		opts := append(p.opts, connect.WithInterceptors(grpcdump.ConnectInterceptor()))
+
+		t.Run(p.name, func(t *testing.T) {
+			t.Run("unary", func(t *testing.T) {
+				ctx := grpcdump.NewRecorder(t, ctx, dumpOpts...)
+
+				client := connect.NewClient[pb.SayHelloRequest, pb.SayHelloResponse](ts.Client(), ts.URL+sayHelloProcedure, opts...)
+				req := connect.NewRequest(&pb.SayHelloRequest{Name: "John Doe"})
+				req.Header().Set("md-key", "md-val")
+				req.Header().Set("md-key-bin", connect.EncodeBinaryHeader([]byte("md-val-bin")))
+
+				_, err := client.CallUnary(ctx, req)
+				assert.Nil(t, err)
+			})
+
+			t.Run("server stream", func(t *testing.T) {
+				ctx := grpcdump.NewRecorder(t, ctx, dumpOpts...)
+
+				client := connect.NewClient[pb.ListGreetingsRequest, pb.ListGreetingsResponse](ts.Client(), ts.URL+listGreetingsProcedure, opts...)
+				stream, err := client.CallServerStream(ctx, connect.NewRequest(&pb.ListGreetingsRequest{Count: 2}))
+				assert.Nil(t, err)
+
+				for stream.Receive() {
+				}
+				assert.Nil(t, stream.Err())
+				assert.Nil(t, stream.Close())
+			})
+
+			t.Run("failed", func(t *testing.T) {
+				ctx := grpcdump.NewRecorder(t, ctx, dumpOpts...)
+
+				client := connect.NewClient[pb.SayHelloRequest, pb.SayHelloResponse](ts.Client(), ts.URL+sayHelloProcedure, opts...)
+				_, err := client.CallUnary(ctx, connect.NewRequest(&pb.SayHelloRequest{}))
+				assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+			})
+		})
+	}
+
+	t.Run("client only", func(t *testing.T) {
+		// The handler is not intercepted, e.g. third-party services.
+		ts := newConnectServer(t)
+
+		ctx := grpcdump.NewRecorder(t, ctx,
+			grpcdump.IgnoreMetadata("user-agent", "x-user-agent"),
+			grpcdump.IgnoreHeader("date"),
+			// The port of the test server is random.
+			grpcdump.IgnoreAuthority(),
+		)
+
+		client := connect.NewClient[pb.SayHelloRequest, pb.SayHelloResponse](ts.Client(), ts.URL+sayHelloProcedure,
+			connect.WithInterceptors(grpcdump.ConnectInterceptor()),
+		)
+		_, err := client.CallUnary(ctx, connect.NewRequest(&pb.SayHelloRequest{Name: "John Doe"}))
+		assert.Nil(t, err)
+	})
+
+	t.Run("client only compressed", func(t *testing.T) {
+		ts := newConnectServer(t)
+
+		// The call is dumped when the subtest finishes.
+		t.Run("gzip", func(t *testing.T) {
+			ctx := grpcdump.NewRecorder(t, ctx,
+				grpcdump.IgnoreMetadata("user-agent", "x-user-agent"),
+				grpcdump.IgnoreHeader("date"),
+				// The port of the test server is random.
+				grpcdump.IgnoreAuthority(),
+			)
+
+			client := connect.NewClient[pb.SayHelloRequest, pb.SayHelloResponse](ts.Client(), ts.URL+sayHelloProcedure,
+				connect.WithInterceptors(grpcdump.ConnectInterceptor()),
+				connect.WithSendGzip(),
+			)
+			_, err := client.CallUnary(ctx, connect.NewRequest(&pb.SayHelloRequest{Name: "John Doe"}))
+			assert.Nil(t, err)
+		})
+
+		b, err := os.ReadFile("testdata/TestConnect/client_only_compressed/gzip.grpc")
+		assert.Nil(t, err)
+
+		g, err := grpcdump.Read(b)
+		assert.Nil(t, err)
+		assert.Equal(t, "gzip", g.Compressor)
+		assert.True(t, strings.HasPrefix(g.Authority, "127.0.0.1:"), g.Authority)
+	})
+}
+
+func newConnectServer(t *testing.T, opts ...connect.HandlerOption) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.Handle(sayHelloProcedure, connect.NewUnaryHandler(sayHelloProcedure, connectSayHello, opts...))
+	mux.Handle(listGreetingsProcedure, connect.NewServerStreamHandler(listGreetingsProcedure, connectListGreetings, opts...))
+
+	// HTTP/2 is required for the gRPC protocol.
+	ts := httptest.NewUnstartedServer(mux)
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func connectSayHello(ctx context.Context, req *connect.Request[pb.SayHelloRequest]) (*connect.Response[pb.SayHelloResponse], error) {
+	if req.Msg.GetName() == "" {
+		var d errdetails.BadRequest
+		d.FieldViolations = append(d.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       "Name",
+			Description: "Name is required",
+		})
+
+		err := connect.NewError(connect.CodeInvalidArgument, errors.New("Failed to say hello"))
+		detail, derr := connect.NewErrorDetail(&d)
+		if derr != nil {
+			panic(derr)
+		}
+		err.AddDetail(detail)
+
+		return nil, err
+	}
+
+	res := connect.NewResponse(&pb.SayHelloResponse{
+		Message: "Hello " + req.Msg.GetName(),
+	})
+	res.Header().Set("header-key", "header-val")
+	res.Trailer().Set("trailer-key", "trailer-val")
+
+	return res, nil
+}
+
+func connectListGreetings(ctx context.Context, req *connect.Request[pb.ListGreetingsRequest], stream *connect.ServerStream[pb.ListGreetingsResponse]) error {
+	stream.ResponseHeader().Set("header-key", "header-val")
+
+	for i := range req.Msg.GetCount() {
+		err := stream.Send(&pb.ListGreetingsResponse{
+			Message: fmt.Sprintf("hi sir (%d)", i+1),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	stream.ResponseTrailer().Set("trailer-key", "trailer-val")
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package grpcdump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// descriptors resolves the method descriptors from the provided files, or
+// from the global registry when no files are provided.
+type descriptors struct {
+	mu    sync.Mutex
+	files *protoregistry.Files
+	// global resolves the services from the global registry.
+	global bool
+	// load fetches the files that defines the service, e.g. using server
+	// reflection.
+	load func(ctx context.Context, service string) ([]*descriptorpb.FileDescriptorProto, error)
+}
+
+func newDescriptors(fds []*descriptorpb.FileDescriptorProto) (*descriptors, error) {
+	d := &descriptors{
+		files:  new(protoregistry.Files),
+		global: len(fds) == 0,
+	}
+	if err := d.register(fds); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// findMethod returns the method descriptor of the full method, e.g.
+// `/helloworld.v1.GreeterService/SayHello`.
+func (d *descriptors) findMethod(ctx context.Context, fullMethod string) (protoreflect.MethodDescriptor, error) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("grpcdump: invalid method %q", fullMethod)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	desc, err := d.findService(service)
+	if errors.Is(err, protoregistry.NotFound) && d.load != nil {
+		fds, lerr := d.load(ctx, service)
+		if lerr != nil {
+			return nil, lerr
+		}
+		if lerr := d.register(fds); lerr != nil {
+			return nil, lerr
+		}
+
+		desc, err = d.findService(service)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("grpcdump: %s is not a service", service)
+	}
+
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("grpcdump: method %s not found", fullMethod)
+	}
+
+	return md, nil
+}
+
+func (d *descriptors) findService(service string) (protoreflect.Descriptor, error) {
+	if d.global {
+		return protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	}
+
+	return d.files.FindDescriptorByName(protoreflect.FullName(service))
+}
+
+// resolver resolves the dependencies of the files, e.g. well-known types,
+// from the global registry too.
+func (d *descriptors) resolver() resolver {
+	return resolver{d.files, protoregistry.GlobalFiles}
+}
+
+// register registers the files in the order of their dependencies.
+func (d *descriptors) register(fds []*descriptorpb.FileDescriptorProto) error {
+	byName := make(map[string]*descriptorpb.FileDescriptorProto)
+	for _, fd := range fds {
+		byName[fd.GetName()] = fd
+	}
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if _, err := d.files.FindFileByPath(name); err == nil {
+			return nil
+		}
+
+		fd, ok := byName[name]
+		if !ok {
+			// Resolved from the global registry.
+			return nil
+		}
+		delete(byName, name)
+
+		for _, dep := range fd.GetDependency() {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		f, err := protodesc.NewFile(fd, d.resolver())
+		if err != nil {
+			return err
+		}
+
+		return d.files.RegisterFile(f)
+	}
+
+	for _, fd := range fds {
+		if err := visit(fd.GetName()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolver looks up the descriptors from the registries in order.
+type resolver []*protoregistry.Files
+
+func (r resolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	for _, files := range r {
+		if fd, err := files.FindFileByPath(path); err == nil {
+			return fd, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", path, protoregistry.NotFound)
+}
+
+func (r resolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	for _, files := range r {
+		if desc, err := files.FindDescriptorByName(name); err == nil {
+			return desc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", name, protoregistry.NotFound)
+}
+
+// reflectionLoader fetches the file that defines the service, and its
+// dependencies, from the server reflection service.
+func reflectionLoader(cc grpc.ClientConnInterface) func(context.Context, string) ([]*descriptorpb.FileDescriptorProto, error) {
+	return func(ctx context.Context, service string) ([]*descriptorpb.FileDescriptorProto, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := rpb.NewServerReflectionClient(cc).ServerReflectionInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		err = stream.Send(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{
+				FileContainingSymbol: service,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		_ = stream.CloseSend()
+
+		if e := res.GetErrorResponse(); e != nil {
+			return nil, fmt.Errorf("grpcdump: reflection: %s", e.GetErrorMessage())
+		}
+
+		var fds []*descriptorpb.FileDescriptorProto
+		for _, b := range res.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := new(descriptorpb.FileDescriptorProto)
+			if err := proto.Unmarshal(b, fd); err != nil {
+				return nil, err
+			}
+
+			fds = append(fds, fd)
+		}
+
+		return fds, nil
+	}
+}
@@ -8,9 +8,11 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/tools/txtar"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 var (
@@ -19,11 +21,18 @@ var (
 
 type encoder struct {
 	marshalFns []func(*GRPC) error
+	protojson  protojson.MarshalOptions
 }
 
 func (e *encoder) Marshal(v any) ([]byte, error) {
 	g := v.(*GRPC)
 
+	msgs, err := renderMessages(e.protojson, g.Messages...)
+	if err != nil {
+		return nil, err
+	}
+	g.Messages = msgs
+
 	for _, fn := range e.marshalFns {
 		if err := fn(g); err != nil {
 			return nil, err
@@ -61,7 +70,20 @@ type GRPC struct {
 	Trailer        metadata.MD `json:"trailer"`
 	IsServerStream bool        `json:"isServerStream"`
 	IsClientStream bool        `json:"isClientStream"`
+
+	// Optional call options.
+	Protocol   string        `json:"protocol,omitempty"` // grpc, grpcweb or connect.
+	Authority  string        `json:"authority,omitempty"`
+	Deadline   time.Duration `json:"deadline,omitempty"` // The time left before the deadline.
+	Compressor string        `json:"compressor,omitempty"`
 	//HeaderIdx      int         `json:"-"`
+
+	// missing is the call sections missing from older snapshots, see Read.
+	missing []string
+
+	// err is the error when recording the call, e.g. the proxy failed to
+	// resolve the method descriptor.
+	err error
 }
 
 // Service is a method on the GRPC struct.
@@ -84,9 +106,11 @@ func Read(b []byte) (*GRPC, error) {
 	arc := txtar.Parse(b)
 
 	g := new(GRPC)
+	seen := make(map[string]bool)
 	for _, f := range arc.Files {
 		name, typ, _ := strings.Cut(f.Name, "/")
 		data := bytes.TrimSpace(f.Data)
+		seen[name] = true
 		switch name {
 		case lineFile:
 			text := string(data)
@@ -113,6 +137,29 @@ func Read(b []byte) (*GRPC, error) {
 				return nil, err
 			}
 			g.Trailer = md
+		case protocolFile:
+			g.Protocol = string(data)
+		case authorityFile:
+			g.Authority = string(data)
+		case deadlineFile:
+			d, err := time.ParseDuration(string(data))
+			if err != nil {
+				return nil, err
+			}
+			g.Deadline = d
+		case compressorFile:
+			g.Compressor = string(data)
+		case sizeFile:
+			sizes, err := readSizes(data)
+			if err != nil {
+				return nil, err
+			}
+			if len(sizes) != len(g.Messages) {
+				return nil, fmt.Errorf("grpcdump: got %d sizes, want %d", len(sizes), len(g.Messages))
+			}
+			for i, n := range sizes {
+				g.Messages[i].Size = n
+			}
 		case statusFile:
 			if err := json.Unmarshal(data, &g.Status); err != nil {
 				return nil, err
@@ -145,6 +192,8 @@ func Read(b []byte) (*GRPC, error) {
 		}
 	}
 
+	g.missing = missingCallInfo(seen)
+
 	return g, nil
 }
 
@@ -168,6 +217,7 @@ func Write(g *GRPC) ([]byte, error) {
 	}
 	files = append(files, status)
 	files = append(files, writeMetadata(trailerFile, g.Trailer))
+	files = append(files, writeCallInfo(g)...)
 
 	arc := new(txtar.Archive)
 	for _, f := range files {
@@ -217,7 +267,28 @@ func writeMetadata(file string, md metadata.MD) txtar.File {
 	}
 }
 
+// renderMessages converts the proto messages into maps.
+func renderMessages(opts protojson.MarshalOptions, msgs ...Message) ([]Message, error) {
+	res := make([]Message, len(msgs))
+	for i, msg := range msgs {
+		a, err := toMap(msg.Message, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		msg.Message = a
+		res[i] = msg
+	}
+
+	return res, nil
+}
+
 func writeMessages(isClientStream, isServerStream bool, msgs ...Message) ([]txtar.File, error) {
+	msgs, err := renderMessages(protojson.MarshalOptions{UseProtoNames: true}, msgs...)
+	if err != nil {
+		return nil, err
+	}
+
 	files := make([]txtar.File, len(msgs))
 	for i, msg := range msgs {
 		// Pretty print the message.
@@ -226,24 +297,13 @@ func writeMessages(isClientStream, isServerStream bool, msgs ...Message) ([]txta
 			return nil, err
 		}
 
-		var prefix string
-		isServer := msg.Origin == OriginServer
-		if isServer && isServerStream {
-			prefix = serverStreamFile
-		} else if isServer && !isServerStream {
-			prefix = serverFile
-		} else if !isServer && isClientStream {
-			prefix = clientStreamFile
-		} else if !isServer && !isClientStream {
-			prefix = clientFile
-		} else {
-			return nil, fmt.Errorf("grpcdump: unknown message origin")
-		}
-
 		// E.,g.
 		// server/helloworld.v1.ChatRequest
 		// server stream/helloworld.v1.ChatRequest
-		header := filepath.Join(prefix, msg.Name)
+		header := messageFile(&GRPC{
+			IsClientStream: isClientStream,
+			IsServerStream: isServerStream,
+		}, msg)
 
 		files[i] = txtar.File{
 			Name: header,
@@ -254,6 +314,22 @@ func writeMessages(isClientStream, isServerStream bool, msgs ...Message) ([]txta
 	return files, nil
 }
 
+func messageFile(g *GRPC, msg Message) string {
+	var prefix string
+	isServer := msg.Origin == OriginServer
+	if isServer && g.IsServerStream {
+		prefix = serverStreamFile
+	} else if isServer {
+		prefix = serverFile
+	} else if g.IsClientStream {
+		prefix = clientStreamFile
+	} else {
+		prefix = clientFile
+	}
+
+	return filepath.Join(prefix, msg.Name)
+}
+
 func writeStatus(status *Status) (txtar.File, error) {
 	if status == nil {
 		return txtar.File{}, nil
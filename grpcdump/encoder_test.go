@@ -1,9 +1,12 @@
 package grpcdump_test
 
 import (
+	"os"
 	"testing"
+	"time"
 
 	"github.com/alextanhongpin/testdump/grpcdump"
+	pb "github.com/alextanhongpin/testdump/grpcdump/testdata/helloworld/v1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 )
@@ -58,3 +61,65 @@ func TestDump(t *testing.T) {
 	}
 	t.Log(g)
 }
+
+func TestReadProto(t *testing.T) {
+	b, err := os.ReadFile("testdata/TestGRPCUnary/success.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := grpcdump.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := g.Messages[0].Proto()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, ok := msg.(*pb.SayHelloRequest)
+	if !ok {
+		t.Fatalf("want *pb.SayHelloRequest, got %T", msg)
+	}
+	if want, got := "John Doe", req.GetName(); want != got {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+
+	t.Run("unknown type", func(t *testing.T) {
+		m := grpcdump.Message{
+			Name:    "unknown.Message",
+			Message: map[string]any{},
+		}
+		if _, err := m.Proto(); err == nil {
+			t.Fatal("want error, got nil")
+		}
+	})
+}
+
+func TestReadCallOptions(t *testing.T) {
+	b, err := os.ReadFile("testdata/TestCallOptions/deadline_and_compressor.grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := grpcdump.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := "x.test.example.com", g.Authority; want != got {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+	if want, got := 5*time.Second, g.Deadline; want != got {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+	if want, got := "gzip", g.Compressor; want != got {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+	for _, msg := range g.Messages {
+		if msg.Size == 0 {
+			t.Fatalf("want size, got 0 for %s", msg.Name)
+		}
+	}
+}
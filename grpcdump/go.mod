@@ -5,6 +5,7 @@ go 1.24.0
 toolchain go1.24.2
 
 require (
+	connectrpc.com/connect v1.19.1
 	github.com/alextanhongpin/testdump/pkg/diff v0.0.0-20260202052708-05da09e3b52b
 	github.com/alextanhongpin/testdump/pkg/file v0.0.0-20260202052708-05da09e3b52b
 	github.com/alextanhongpin/testdump/pkg/reviver v0.0.0-20260202052708-05da09e3b52b
@@ -14,7 +14,10 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 
 	"github.com/alextanhongpin/testdump/pkg/file"
 	"github.com/alextanhongpin/testdump/pkg/snapshot"
@@ -27,6 +30,8 @@ const OriginClient = "client"
 
 const grpcdumpTestID = "x-grpcdump-testid"
 
+const grpcStatusDetailsKey = "grpc-status-details-bin"
+
 // NOTE: hackish implementation to extract the dump from the grpc server.
 var testIds = make(map[string]*GRPC)
 var mu sync.Mutex
@@ -60,6 +65,10 @@ func (d *Dumper) Record(t *testing.T, ctx context.Context, opts ...Option) conte
 		delete(testIds, id)
 		mu.Unlock()
 
+		if g2c != nil && g2c.err != nil {
+			t.Error(g2c.err)
+		}
+
 		if err := d.dump(t, g2c, opts...); err != nil {
 			t.Error(err)
 		}
@@ -95,6 +104,7 @@ type Message struct {
 	Origin  string `json:"origin"` // server or client
 	Name    string `json:"name"`   // message type (protobuf name)
 	Message any    `json:"message"`
+	Size    int    `json:"size,omitempty"` // The size of the message on the wire, before compression.
 }
 
 type serverStreamInterceptor struct {
@@ -163,23 +173,26 @@ func WithUnaryInterceptor() grpc.DialOption {
 	return grpc.WithUnaryInterceptor(UnaryClientInterceptor)
 }
 
+// WithStreamInterceptor is a function that returns a grpc.DialOption.
+// This DialOption, when applied, configures the client to use the StreamClientInterceptor function as the stream interceptor.
+// The stream interceptor is a function that intercepts outgoing streaming RPCs on the client.
+func WithStreamInterceptor() grpc.DialOption {
+	return grpc.WithStreamInterceptor(StreamClientInterceptor)
+}
+
 // StreamServerInterceptor is a function that intercepts incoming streaming RPCs on the server.
 // It takes a server, a grpc.ServerStream, a grpc.StreamServerInfo, and a grpc.StreamHandler, and returns an error.
 // If the interception is successful, it should return nil.
 // If the interception fails, it should return an error.
 func StreamServerInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 	ctx := stream.Context()
-	md, ok := metadata.FromIncomingContext(ctx)
+	md, id, ok := testIDFromIncomingContext(ctx)
 	if !ok {
-		return ErrMetadataNotFound
+		// Pass through calls that are not recorded.
+		return handler(srv, stream)
 	}
 
-	// Extract the test-id from the header.
-	// We do not want to log this, so delete it from the
-	// existing header.
-	id := md.Get(grpcdumpTestID)[0]
-	md.Delete(grpcdumpTestID)
-
+	deadline := deadlineFromContext(ctx)
 	w := &serverStreamInterceptor{
 		ServerStream: stream,
 	}
@@ -196,6 +209,9 @@ func StreamServerInterceptor(srv any, stream grpc.ServerStream, info *grpc.Strea
 		Status:         newStatus(err),
 		IsServerStream: info.IsServerStream,
 		IsClientStream: info.IsClientStream,
+		Protocol:       ProtocolGRPC,
+		Authority:      authorityFromIncomingContext(ctx),
+		Deadline:       deadline,
 	}
 	mu.Unlock()
 
@@ -207,17 +223,13 @@ func StreamServerInterceptor(srv any, stream grpc.ServerStream, info *grpc.Strea
 // If the interception is successful, it should return the response and nil.
 // If the interception fails, it should return nil and the error.
 func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-	md, ok := metadata.FromIncomingContext(ctx)
+	md, id, ok := testIDFromIncomingContext(ctx)
 	if !ok {
-		return nil, ErrMetadataNotFound
+		// Pass through calls that are not recorded.
+		return handler(ctx, req)
 	}
 
-	// Extract the test-id from the header.
-	// We do not want to log this, so delete it from the
-	// existing header.
-	id := md.Get(grpcdumpTestID)[0]
-	md.Delete(grpcdumpTestID)
-
+	deadline := deadlineFromContext(ctx)
 	res, err := handler(ctx, req)
 	messages := []Message{origin(OriginClient, req)}
 
@@ -232,6 +244,9 @@ func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.Una
 		Metadata:   md,
 		Messages:   messages,
 		Status:     newStatus(err),
+		Protocol:   ProtocolGRPC,
+		Authority:  authorityFromIncomingContext(ctx),
+		Deadline:   deadline,
 	}
 	mu.Unlock()
 
@@ -240,74 +255,202 @@ func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.Una
 
 // UnaryClientInterceptor is a function that intercepts outgoing unary RPCs on the client.
 // It takes a context, a method string, a request, a response, a grpc.ClientConn, a grpc.UnaryInvoker, and a slice of grpc.CallOption, and returns an error.
-// If the interception is successful, it should return nil.
-// If the interception fails, it should return the error.
+// If the server is not intercepted, e.g. third-party services, the call is recorded from the client side.
+// Calls that are not recorded are passed through.
 func UnaryClientInterceptor(ctx context.Context, method string, req, res any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-	md, ok := metadata.FromOutgoingContext(ctx)
+	md, id, ok := testIDFromOutgoingContext(ctx)
 	if !ok {
-		return ErrMetadataNotFound
+		return invoker(ctx, method, req, res, cc, opts...)
 	}
 
-	testID := md.Get(grpcdumpTestID)[0]
-
-	ctx = metadata.NewOutgoingContext(ctx, md)
+	deadline := deadlineFromContext(ctx)
+	compressor := compressorFromCallOptions(opts)
 
 	var header, trailer metadata.MD
-	opts = append(opts, grpc.Header(&header), grpc.Trailer(&trailer))
+	var pr peer.Peer
+	opts = append(opts, grpc.Header(&header), grpc.Trailer(&trailer), grpc.Peer(&pr))
 
-	if err := invoker(ctx, method, req, res, cc, opts...); err != nil {
-		return err
-	}
+	err := invoker(ctx, method, req, res, cc, opts...)
 
 	header.Delete(grpcdumpTestID)
 
 	mu.Lock()
-	testIds[testID].Trailer = trailer
-	testIds[testID].Header = header
-	mu.Unlock()
+	defer mu.Unlock()
+
+	// The call is already recorded by the server.
+	if g, ok := testIds[id]; ok {
+		// The compressor is only known to the client.
+		g.Compressor = compressor
+		if err == nil {
+			g.Trailer = trailer
+			g.Header = header
+		}
+
+		return err
+	}
+
+	messages := []Message{origin(OriginClient, req)}
+	if err == nil {
+		messages = append(messages, origin(OriginServer, res))
+	}
+
+	testIds[id] = &GRPC{
+		Addr:       addrFromPeer(&pr),
+		FullMethod: method,
+		Metadata:   md,
+		Messages:   messages,
+		Header:     header,
+		Trailer:    clientTrailer(trailer),
+		Status:     newStatus(err),
+		Protocol:   ProtocolGRPC,
+		Authority:  authorityFromTarget(cc.Target()),
+		Deadline:   deadline,
+		Compressor: compressor,
+	}
+
+	return err
+}
+
+// clientTrailer removes the status details from the trailer received by the
+// client, since they are already written to the status.
+func clientTrailer(md metadata.MD) metadata.MD {
+	if len(md.Get(grpcStatusDetailsKey)) == 0 {
+		return md
+	}
+
+	md = md.Copy()
+	md.Delete(grpcStatusDetailsKey)
+	if md.Len() == 0 {
+		return nil
+	}
 
-	return nil
+	return md
+}
+
+// testIDFromIncomingContext returns the incoming metadata without the test-id,
+// and the test-id.
+func testIDFromIncomingContext(ctx context.Context) (metadata.MD, string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, "", false
+	}
+
+	md, id, ok := cutTestID(md)
+	if ok {
+		// The accepted encodings depends on the compressors registered in the
+		// binary, not the call. The compressor used is recorded separately.
+		md.Delete("grpc-accept-encoding")
+	}
+
+	return md, id, ok
+}
+
+// testIDFromOutgoingContext is similar to testIDFromIncomingContext, but for
+// the outgoing metadata.
+// The test-id is still sent, so that the server can record the call.
+func testIDFromOutgoingContext(ctx context.Context) (metadata.MD, string, bool) {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return nil, "", false
+	}
+
+	return cutTestID(md)
+}
+
+func cutTestID(md metadata.MD) (metadata.MD, string, bool) {
+	ids := md.Get(grpcdumpTestID)
+	if len(ids) == 0 {
+		return nil, "", false
+	}
+
+	// We do not want to log this, so delete it from the copy.
+	md = md.Copy()
+	md.Delete(grpcdumpTestID)
+
+	return md, ids[0], true
 }
 
 func addrFromContext(ctx context.Context) string {
-	var addr string
-	if pr, ok := peer.FromContext(ctx); ok {
-		if tcpAddr, ok := pr.Addr.(*net.TCPAddr); ok {
-			addr = tcpAddr.IP.String()
-		} else {
-			addr = pr.Addr.String()
-		}
+	pr, _ := peer.FromContext(ctx)
+	return addrFromPeer(pr)
+}
+
+// addrFromPeer returns the IP of the peer, without the port.
+// The port of the client is ephemeral, and the port of test servers is
+// usually random, so recording it would change the snapshot on every run.
+// The port dialed by the client is recorded in the authority instead.
+func addrFromPeer(pr *peer.Peer) string {
+	if pr == nil || pr.Addr == nil {
+		return ""
+	}
+
+	if tcpAddr, ok := pr.Addr.(*net.TCPAddr); ok {
+		return tcpAddr.IP.String()
 	}
-	return addr
+
+	return pr.Addr.String()
 }
 
 func origin(origin string, v any) Message {
-	msg, ok := v.(interface {
-		ProtoReflect() protoreflect.Message
-	})
+	msg, ok := v.(proto.Message)
 	if !ok {
 		panic("grpcdump: message is not valid")
 	}
-	m, err := toMap(v)
-	if err != nil {
-		panic(err)
-	}
 
+	// The message is rendered when dumped, so that the protojson options can
+	// be configured per test.
 	return Message{
 		Origin:  origin,
 		Name:    fmt.Sprint(msg.ProtoReflect().Descriptor().FullName()),
-		Message: m,
+		Message: proto.Clone(msg),
+		Size:    proto.Size(msg),
 	}
 }
 
-func toMap(v any) (any, error) {
-	b, err := json.Marshal(v)
+// toMap renders the proto message with protojson, so that the fields are
+// compared by their JSON names.
+// Values that are not proto messages, e.g. from Read, are returned as it is.
+func toMap(v any, opts protojson.MarshalOptions) (any, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return v, nil
+	}
+
+	b, err := opts.Marshal(msg)
 	if err != nil {
 		return nil, err
 	}
+
 	var a any
 	if err := json.Unmarshal(b, &a); err != nil {
 		return nil, err
 	}
+
 	return a, nil
 }
+
+// Proto rehydrates the message into the concrete proto type registered under
+// the message name.
+// It returns an error if the message type is not found in the registry.
+func (m Message) Proto() (proto.Message, error) {
+	if msg, ok := m.Message.(proto.Message); ok {
+		return msg, nil
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(m.Name))
+	if err != nil {
+		return nil, fmt.Errorf("grpcdump: %s: %w", m.Name, err)
+	}
+
+	b, err := json.Marshal(m.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := mt.New().Interface()
+	if err := protojson.Unmarshal(b, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
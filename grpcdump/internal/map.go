@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/alextanhongpin/testdump/pkg/reviver"
+)
+
+// LoadMapValues returns the values at the given dotted paths.
+func LoadMapValues(a any, paths ...string) map[string]any {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	res := make(map[string]any)
+	_ = reviver.Walk(a, func(keys []string, v any) error {
+		k := strings.Join(keys, ".")
+		if slices.Contains(paths, k) {
+			res[k] = v
+		}
+
+		// Terminate early
+		if len(res) == len(paths) {
+			return io.EOF
+		}
+		return nil
+	})
+
+	return res
+}
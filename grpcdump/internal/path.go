@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// IgnorePaths ignores the values at the given paths, relative to the
+// innermost struct field, e.g. the message of a gRPC message.
+// The paths are dotted, with arrays indexed by `[i]`, e.g. `data.users[0].id`.
+func IgnorePaths(paths ...string) cmp.Option {
+	return cmp.FilterPath(func(p cmp.Path) bool {
+		return slices.Contains(paths, JoinPath(p))
+	}, cmp.Ignore())
+}
+
+// JoinPath formats the map keys and slice indices of the cmp.Path into a
+// dotted path, in the same format as the reviver package.
+func JoinPath(p cmp.Path) string {
+	var keys []string
+	for _, s := range p {
+		switch s := s.(type) {
+		case cmp.StructField:
+			// Start from the struct field.
+			keys = nil
+		case cmp.MapIndex:
+			keys = append(keys, fmt.Sprint(s.Key()))
+		case cmp.SliceIndex:
+			i, j := s.SplitKeys()
+			if i < 0 {
+				i = j
+			}
+
+			idx := fmt.Sprintf("[%d]", i)
+			if len(keys) == 0 {
+				keys = append(keys, idx)
+			} else {
+				keys[len(keys)-1] += idx
+			}
+		}
+	}
+
+	return strings.Join(keys, ".")
+}
@@ -3,10 +3,13 @@ package grpcdump
 import (
 	"encoding/json"
 	"os"
+	"slices"
 	"strconv"
 
 	"github.com/alextanhongpin/testdump/grpcdump/internal"
 	"github.com/alextanhongpin/testdump/pkg/reviver"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 const env = "TESTDUMP"
@@ -20,12 +23,18 @@ type options struct {
 	colors       bool
 	env          string
 	transformers []func(*GRPC) error
+	protojson    protojson.MarshalOptions
+	order        messageOrder
 }
 
 func newOptions() *options {
 	return &options{
 		colors: true,
 		env:    env,
+		protojson: protojson.MarshalOptions{
+			// Keep the field names consistent with the proto definition.
+			UseProtoNames: true,
+		},
 	}
 }
 
@@ -44,6 +53,7 @@ func (o *options) overwrite() bool {
 func (o *options) encoder() *encoder {
 	return &encoder{
 		marshalFns: o.transformers,
+		protojson:  o.protojson,
 	}
 }
 
@@ -51,6 +61,24 @@ func (o *options) comparer() *comparer {
 	return &comparer{
 		opt:    o.cmpOpt,
 		colors: o.colors,
+		order:  o.order,
+	}
+}
+
+// UseProtoNames is a function that returns an Option.
+// This Option, when applied, configures the messages to be rendered with the proto field names instead of the lowerCamelCase JSON names.
+// Defaults to true.
+func UseProtoNames(use bool) Option {
+	return func(o *options) {
+		o.protojson.UseProtoNames = use
+	}
+}
+
+// EmitUnpopulated is a function that returns an Option.
+// This Option, when applied, configures the messages to be rendered with the zero value fields.
+func EmitUnpopulated(emit bool) Option {
+	return func(o *options) {
+		o.protojson.EmitUnpopulated = emit
 	}
 }
 
@@ -92,6 +120,136 @@ func IgnoreMessageFields(keys ...string) Option {
 	}
 }
 
+// IgnoreMessagePaths is a function that returns an Option.
+// This Option, when applied, configures the options object to ignore certain message paths.
+// The paths are dotted, with arrays indexed by `[i]`, e.g. `user.addresses[0].city`.
+func IgnoreMessagePaths(paths ...string) Option {
+	return func(o *options) {
+		o.cmpOpt.Message = append(o.cmpOpt.Message, internal.IgnorePaths(paths...))
+	}
+}
+
+// ForOrigin is a function that returns an Option.
+// This Option, when applied, scopes the message options to the messages from the origin, either OriginClient or OriginServer.
+// Options that are not related to messages are applied as usual.
+func ForOrigin(origin string, opts ...Option) Option {
+	return scopeMessages(func(_ int, msg Message) bool {
+		return msg.Origin == origin
+	}, opts...)
+}
+
+// ForMessage is a function that returns an Option.
+// This Option, when applied, scopes the message options to the message at the index, in the order they are written to the snapshot, starting from 0.
+// Options that are not related to messages are applied as usual.
+func ForMessage(index int, opts ...Option) Option {
+	return scopeMessages(func(i int, _ Message) bool {
+		return i == index
+	}, opts...)
+}
+
+func scopeMessages(pred func(int, Message) bool, opts ...Option) Option {
+	return func(o *options) {
+		scoped := new(options).apply(opts...)
+
+		for _, opt := range scoped.cmpOpt.Message {
+			o.cmpOpt.Message = append(o.cmpOpt.Message, filterMessages(pred, opt))
+		}
+		o.cmpOpt.Status = append(o.cmpOpt.Status, scoped.cmpOpt.Status...)
+		o.cmpOpt.Metadata = append(o.cmpOpt.Metadata, scoped.cmpOpt.Metadata...)
+		o.cmpOpt.Header = append(o.cmpOpt.Header, scoped.cmpOpt.Header...)
+		o.cmpOpt.Trailer = append(o.cmpOpt.Trailer, scoped.cmpOpt.Trailer...)
+
+		for _, fn := range scoped.transformers {
+			o.transformers = append(o.transformers, func(g *GRPC) error {
+				// Only pass the matching messages to the transformer.
+				var idx []int
+				var msgs []Message
+				for i, msg := range g.Messages {
+					if pred(i, msg) {
+						idx = append(idx, i)
+						msgs = append(msgs, msg)
+					}
+				}
+
+				gc := *g
+				gc.Messages = msgs
+				if err := fn(&gc); err != nil {
+					return err
+				}
+
+				// Merge the transformed messages back.
+				msgs = slices.Clone(g.Messages)
+				for j, i := range idx {
+					msgs[i] = gc.Messages[j]
+				}
+				gc.Messages = msgs
+				*g = gc
+
+				return nil
+			})
+		}
+	}
+}
+
+// IgnoreInterleaving is a function that returns an Option.
+// This Option, when applied, compares the client and server messages separately, so that the interleaving of the messages in bidirectional streams can vary.
+// The order of the messages from the same origin is still compared.
+func IgnoreInterleaving() Option {
+	return func(o *options) {
+		o.order.ignoreInterleaving = true
+	}
+}
+
+// UnorderedMessages is a function that returns an Option.
+// This Option, when applied, compares the messages from the same origin as a multiset, e.g. for server streams that sends messages from multiple goroutines.
+// The messages are sorted by the values at the paths, if provided, then by the whole message. Provide the paths when the messages contains ignored fields.
+// Note that ForMessage refers to the index after sorting.
+func UnorderedMessages(sortPaths ...string) Option {
+	return func(o *options) {
+		o.order.unordered = true
+		o.order.sortPaths = append(o.order.sortPaths, sortPaths...)
+	}
+}
+
+// IgnoreStatusDetailFields is a function that returns an Option.
+// This Option, when applied, configures the options object to ignore certain fields in the status details.
+// The fields to ignore are provided as arguments to the function.
+func IgnoreStatusDetailFields(keys ...string) Option {
+	return func(o *options) {
+		o.cmpOpt.Status = append(o.cmpOpt.Status, internal.IgnoreMapEntries(keys...))
+	}
+}
+
+// IgnoreAuthority is a function that returns an Option.
+// This Option, when applied, configures the options object to ignore the `:authority` of the call.
+func IgnoreAuthority() Option {
+	return ignoreCall("Authority")
+}
+
+// IgnoreDeadline is a function that returns an Option.
+// This Option, when applied, configures the options object to ignore the deadline of the call.
+func IgnoreDeadline() Option {
+	return ignoreCall("Deadline")
+}
+
+// IgnoreCompressor is a function that returns an Option.
+// This Option, when applied, configures the options object to ignore the compressor of the call.
+func IgnoreCompressor() Option {
+	return ignoreCall("Compressor")
+}
+
+// IgnoreMessageSizes is a function that returns an Option.
+// This Option, when applied, configures the options object to ignore the wire size of the messages.
+func IgnoreMessageSizes() Option {
+	return ignoreCall("Sizes")
+}
+
+func ignoreCall(field string) Option {
+	return func(o *options) {
+		o.cmpOpt.Call = append(o.cmpOpt.Call, cmpopts.IgnoreFields(callInfo{}, field))
+	}
+}
+
 // MaskMetadata is a function that returns an Option.
 // This Option, when applied, configures the options object to mask certain metadata keys.
 // The mask and the keys to mask are provided as arguments to the function.
@@ -190,3 +348,32 @@ func MaskMessagePaths(mask string, paths []string) Option {
 		})
 	}
 }
+
+// MaskStatusDetailFields is a function that returns an Option.
+// This Option, when applied, configures the options object to mask certain fields in the status details.
+// The mask and the fields to mask are provided as arguments to the function.
+func MaskStatusDetailFields(mask string, fields []string) Option {
+	return func(o *options) {
+		o.transformers = append(o.transformers, func(g *GRPC) error {
+			if g.Status == nil {
+				return nil
+			}
+
+			// Apply the mask to each detail in the status.
+			for i, detail := range g.Status.Details {
+				b, err := reviver.Marshal(detail, internal.MaskFieldsFunc(mask, fields))
+				if err != nil {
+					return err
+				}
+
+				var a any
+				if err := json.Unmarshal(b, &a); err != nil {
+					return err
+				}
+				g.Status.Details[i] = a
+			}
+
+			return nil
+		})
+	}
+}
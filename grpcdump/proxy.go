@@ -0,0 +1,295 @@
+package grpcdump
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProxyOption is a type that defines a function that modifies a proxy.
+type ProxyOption func(p *proxy)
+
+// ProxyFileDescriptorSet is a function that returns a ProxyOption.
+// This ProxyOption, when applied, decodes the messages using the descriptors
+// in the FileDescriptorSet, e.g. generated by `protoc --descriptor_set_out`
+// or `buf build -o`.
+func ProxyFileDescriptorSet(fds *descriptorpb.FileDescriptorSet) ProxyOption {
+	return func(p *proxy) {
+		p.fds = append(p.fds, fds.GetFile()...)
+	}
+}
+
+// ProxyReflection is a function that returns a ProxyOption.
+// This ProxyOption, when applied, decodes the messages using the descriptors
+// fetched from the upstream server reflection service.
+func ProxyReflection() ProxyOption {
+	return func(p *proxy) {
+		p.reflection = true
+	}
+}
+
+// NewProxy is a function that creates a new Server that forwards every call
+// to the upstream connection.
+// The frames are forwarded as it is, without decoding, so the proxy is
+// transparent to the client and the upstream.
+// Calls made with the context from NewRecorder are recorded, and the messages
+// are decoded with the descriptors from the global registry, unless
+// ProxyFileDescriptorSet or ProxyReflection is provided.
+func NewProxy(upstream grpc.ClientConnInterface, opts ...ProxyOption) (*Server, error) {
+	p := &proxy{
+		upstream: upstream,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	var err error
+	p.descriptors, err = newDescriptors(p.fds)
+	if err != nil {
+		return nil, err
+	}
+	if p.reflection {
+		p.descriptors.global = false
+		p.descriptors.load = reflectionLoader(upstream)
+	}
+
+	return &Server{
+		BufSize: bufSize,
+		Server: grpc.NewServer(
+			grpc.ForceServerCodec(rawCodec{}),
+			grpc.UnknownServiceHandler(p.handle),
+		),
+	}, nil
+}
+
+type proxy struct {
+	upstream    grpc.ClientConnInterface
+	fds         []*descriptorpb.FileDescriptorProto
+	reflection  bool
+	descriptors *descriptors
+}
+
+func (p *proxy) handle(_ any, ss grpc.ServerStream) error {
+	ctx := ss.Context()
+	fullMethod, _ := grpc.MethodFromServerStream(ss)
+
+	md, id, recorded := testIDFromIncomingContext(ctx)
+	if !recorded {
+		md, _ = metadata.FromIncomingContext(ctx)
+	}
+
+	deadline := deadlineFromContext(ctx)
+	rec := &proxyRecorder{}
+	if recorded {
+		rec.method, rec.err = p.descriptors.findMethod(ctx, fullMethod)
+	}
+
+	// The test-id is not forwarded to the upstream.
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(ctx, md.Copy()))
+	defer cancel()
+
+	desc := &grpc.StreamDesc{
+		ServerStreams: true,
+		ClientStreams: true,
+	}
+	cs, err := p.upstream.NewStream(ctx, desc, fullMethod, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return err
+	}
+
+	// Forward the client messages to the upstream.
+	// The stream must not be used after the handler returns, so the handler
+	// waits for the goroutine to finish.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for {
+			f := new(frame)
+			if err := ss.RecvMsg(f); err != nil {
+				if errors.Is(err, io.EOF) {
+					_ = cs.CloseSend()
+				} else {
+					cancel()
+				}
+
+				return
+			}
+
+			rec.add(OriginClient, f)
+			if err := cs.SendMsg(f); err != nil {
+				// On io.EOF, the upstream ended the stream, and the status
+				// is returned by cs.RecvMsg.
+				if !errors.Is(err, io.EOF) {
+					cancel()
+				}
+
+				return
+			}
+		}
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Forward the upstream messages to the client.
+	var header metadata.MD
+	for i := 0; ; i++ {
+		f := new(frame)
+		err = cs.RecvMsg(f)
+		if i == 0 {
+			// The header is available after the first message, or when the
+			// stream ends.
+			header, _ = cs.Header()
+			if len(header) > 0 {
+				if err := ss.SendHeader(header); err != nil {
+					return err
+				}
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+
+			break
+		}
+
+		rec.add(OriginServer, f)
+		if err := ss.SendMsg(f); err != nil {
+			return err
+		}
+	}
+
+	trailer := cs.Trailer()
+	ss.SetTrailer(trailer)
+
+	if recorded {
+		g := rec.grpc()
+		g.Addr = addrFromContext(ss.Context())
+		g.FullMethod = fullMethod
+		g.Metadata = md
+		g.Header = header
+		g.Trailer = clientTrailer(trailer)
+		g.Status = newStatus(err)
+		g.Protocol = ProtocolGRPC
+		g.Authority = authorityFromIncomingContext(ss.Context())
+		g.Deadline = deadline
+		g.err = rec.err
+
+		mu.Lock()
+		testIds[id] = g
+		mu.Unlock()
+	}
+
+	return err
+}
+
+// proxyRecorder decodes and records the frames forwarded by the proxy.
+type proxyRecorder struct {
+	method protoreflect.MethodDescriptor
+	err    error
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (r *proxyRecorder) add(origin string, f *frame) {
+	if r.method == nil && r.err == nil {
+		// Not recorded.
+		return
+	}
+
+	msg := r.decode(origin, f)
+
+	r.mu.Lock()
+	r.messages = append(r.messages, msg)
+	r.mu.Unlock()
+}
+
+func (r *proxyRecorder) decode(origin string, f *frame) Message {
+	if r.method != nil {
+		desc := r.method.Input()
+		if origin == OriginServer {
+			desc = r.method.Output()
+		}
+
+		msg := dynamicpb.NewMessage(desc)
+		if err := proto.Unmarshal(f.payload, msg); err == nil {
+			return Message{
+				Origin:  origin,
+				Name:    string(desc.FullName()),
+				Message: msg,
+				Size:    len(f.payload),
+			}
+		}
+	}
+
+	// The descriptor is not available, keep the raw bytes.
+	return Message{
+		Origin: origin,
+		Message: map[string]any{
+			"@bytes": base64.StdEncoding.EncodeToString(f.payload),
+		},
+		Size: len(f.payload),
+	}
+}
+
+func (r *proxyRecorder) grpc() *GRPC {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g := &GRPC{
+		Messages: r.messages,
+	}
+	if r.method != nil {
+		g.IsClientStream = r.method.IsStreamingClient()
+		g.IsServerStream = r.method.IsStreamingServer()
+	}
+
+	return g
+}
+
+// frame is the raw message forwarded by the proxy.
+type frame struct {
+	payload []byte
+}
+
+// rawCodec passes the frames through without decoding.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	f, ok := v.(*frame)
+	if !ok {
+		return proto.Marshal(v.(proto.Message))
+	}
+
+	return f.payload, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	f, ok := v.(*frame)
+	if !ok {
+		return proto.Unmarshal(data, v.(proto.Message))
+	}
+
+	f.payload = append([]byte(nil), data...)
+
+	return nil
+}
+
+// Name returns the same name as the default codec, so that the content-type
+// is unchanged.
+func (rawCodec) Name() string {
+	return "proto"
+}
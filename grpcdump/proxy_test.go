@@ -0,0 +1,121 @@
+package grpcdump_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alextanhongpin/testdump/grpcdump"
+	pb "github.com/alextanhongpin/testdump/grpcdump/testdata/helloworld/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestProxy(t *testing.T) {
+	ctx := context.Background()
+
+	// The upstream is not intercepted, e.g. a vendor service.
+	upstream := grpcdump.NewServer()
+	pb.RegisterGreeterServiceServer(upstream.Server, &server{})
+	reflection.Register(upstream.Server)
+	stop := upstream.ListenAndServe()
+	defer stop()
+
+	cc, err := upstream.DialContext(ctx, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	fds := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(pb.File_v1_helloworld_proto),
+		},
+	}
+
+	tests := []struct {
+		name string
+		opts []grpcdump.ProxyOption
+	}{
+		{name: "global registry"},
+		{name: "file descriptor set", opts: []grpcdump.ProxyOption{grpcdump.ProxyFileDescriptorSet(fds)}},
+		{name: "reflection", opts: []grpcdump.ProxyOption{grpcdump.ProxyReflection()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy, err := grpcdump.NewProxy(cc, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stop := proxy.ListenAndServe()
+			defer stop()
+
+			conn, err := proxy.DialContext(ctx, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			client := pb.NewGreeterServiceClient(conn)
+
+			t.Run("unary", func(t *testing.T) {
+				ctx := grpcdump.NewRecorder(t, ctx, grpcdump.IgnoreMetadata("user-agent"))
+				res, err := client.SayHello(ctx, &pb.SayHelloRequest{
+					Name: "John Doe",
+				})
+				assert.Nil(t, err)
+				assert.Equal(t, "Hello John Doe", res.GetMessage())
+			})
+
+			t.Run("decoded", func(t *testing.T) {
+				b, err := os.ReadFile(filepath.Join("testdata", strings.ReplaceAll(t.Name(), "decoded", "unary.grpc")))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				g, err := grpcdump.Read(b)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if assert.Len(t, g.Messages, 2) {
+					assert.Equal(t, "helloworld.v1.SayHelloRequest", g.Messages[0].Name)
+					assert.Equal(t, "helloworld.v1.SayHelloResponse", g.Messages[1].Name)
+				}
+			})
+
+			t.Run("server stream", func(t *testing.T) {
+				ctx := grpcdump.NewRecorder(t, ctx, grpcdump.IgnoreMetadata("user-agent"))
+				stream, err := client.ListGreetings(ctx, &pb.ListGreetingsRequest{
+					Count: 2,
+				})
+				assert.Nil(t, err)
+
+				var n int
+				for {
+					_, err := stream.Recv()
+					if err == io.EOF {
+						break
+					}
+					assert.Nil(t, err)
+					n++
+				}
+				assert.Equal(t, 2, n)
+			})
+
+			t.Run("not recorded", func(t *testing.T) {
+				_, err := client.SayHello(ctx, &pb.SayHelloRequest{
+					Name: "John Doe",
+				})
+				assert.Nil(t, err)
+			})
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"math/rand"
 	"os"
 	"strings"
 	"testing"
@@ -20,6 +21,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/credentials/oauth"
+	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/examples/data"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -130,6 +132,47 @@ func TestGRPCBidirectionalStreaming(t *testing.T) {
 	<-done
 }
 
+func TestMessageScopeOptions(t *testing.T) {
+	t.Setenv("GODEBUG", "x509sha1=1")
+
+	ctx := context.Background()
+	conn := grpcDialContext(t, ctx)
+
+	// Create a new client.
+	client := pb.NewGreeterServiceClient(conn)
+
+	// The messages are written in the order of:
+	// 0. client: foo
+	// 1. server: REPLY: foo
+	// 2. client: <dynamic>
+	// 3. server: REPLY: <dynamic>
+	ctx = grpcdump.NewRecorder(t, ctx,
+		grpcdump.IgnoreMetadata("user-agent"),
+		grpcdump.ForMessage(2, grpcdump.IgnoreMessagePaths("message")),
+		grpcdump.ForOrigin(grpcdump.OriginServer, grpcdump.MaskMessagePaths("[MASKED]", []string{"message"})),
+		// The length of the dynamic message varies.
+		grpcdump.IgnoreMessageSizes(),
+	)
+
+	assert := assert.New(t)
+	stream, err := client.Chat(ctx)
+	assert.Nil(err)
+
+	for _, msg := range []string{"foo", time.Now().Format(time.RFC3339Nano)} {
+		err := stream.Send(&pb.ChatRequest{
+			Message: msg,
+		})
+		assert.Nil(err)
+
+		_, err = stream.Recv()
+		assert.Nil(err)
+	}
+	stream.CloseSend()
+
+	_, err = stream.Recv()
+	assert.Equal(io.EOF, err)
+}
+
 func TestGRPCServerStreaming(t *testing.T) {
 	t.Setenv("GODEBUG", "x509sha1=1")
 
@@ -190,6 +233,47 @@ func TestGRPCServerStreaming(t *testing.T) {
 	})
 }
 
+func TestStatusDetailOptions(t *testing.T) {
+	t.Setenv("GODEBUG", "x509sha1=1")
+
+	tests := []struct {
+		name string
+		opts []grpcdump.Option
+	}{
+		{
+			name: "mask detail fields",
+			opts: []grpcdump.Option{
+				grpcdump.MaskStatusDetailFields("[MASKED]", []string{"description"}),
+			},
+		},
+		{
+			name: "ignore detail fields",
+			opts: []grpcdump.Option{
+				grpcdump.IgnoreStatusDetailFields("description"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testServerStreaming(t, &pb.ListGreetingsRequest{
+				Count: -1,
+			}, tt.opts...)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestProtoJSONOptions(t *testing.T) {
+	t.Setenv("GODEBUG", "x509sha1=1")
+
+	// The zero count is rendered with EmitUnpopulated.
+	err := testServerStreaming(t, &pb.ListGreetingsRequest{
+		Count: 0,
+	}, grpcdump.EmitUnpopulated(true), grpcdump.UseProtoNames(false))
+	assert.NotNil(t, err)
+}
+
 func TestGRPCUnary(t *testing.T) {
 	t.Setenv("GODEBUG", "x509sha1=1")
 	ctx := context.Background()
@@ -345,9 +429,204 @@ func TestIgnoreOptions(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestClientOnly(t *testing.T) {
+	ctx := context.Background()
+
+	// The server is not intercepted, e.g. third-party services.
+	gs := grpcdump.NewServer()
+	pb.RegisterGreeterServiceServer(gs.Server, &server{})
+	stop := gs.ListenAndServe()
+	defer stop()
+
+	conn, err := gs.DialContext(ctx,
+		grpcdump.WithUnaryInterceptor(),
+		grpcdump.WithStreamInterceptor(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := pb.NewGreeterServiceClient(conn)
+
+	t.Run("unary", func(t *testing.T) {
+		ctx := grpcdump.NewRecorder(t, ctx)
+		_, err := client.SayHello(ctx, &pb.SayHelloRequest{
+			Name: "John Doe",
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("server stream", func(t *testing.T) {
+		ctx := grpcdump.NewRecorder(t, ctx)
+		stream, err := client.ListGreetings(ctx, &pb.ListGreetingsRequest{
+			Count: 2,
+		})
+		assert.Nil(t, err)
+
+		for {
+			_, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+		}
+	})
+
+	t.Run("client stream", func(t *testing.T) {
+		ctx := grpcdump.NewRecorder(t, ctx)
+		stream, err := client.RecordGreetings(ctx)
+		assert.Nil(t, err)
+
+		for _, msg := range []string{"foo", "bar"} {
+			assert.Nil(t, stream.Send(&pb.RecordGreetingsRequest{
+				Message: msg,
+			}))
+		}
+
+		_, err = stream.CloseAndRecv()
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		ctx := grpcdump.NewRecorder(t, ctx)
+		stream, err := client.ListGreetings(ctx, &pb.ListGreetingsRequest{
+			Count: -1,
+		})
+		assert.Nil(t, err)
+
+		_, err = stream.Recv()
+		assert.NotNil(t, err)
+	})
+
+	t.Run("not recorded", func(t *testing.T) {
+		// The server interceptors pass through calls without the recorder.
+		conn := grpcDialContext(t, ctx)
+		client := pb.NewGreeterServiceClient(conn)
+
+		_, err := client.SayHello(ctx, &pb.SayHelloRequest{
+			Name: "John Doe",
+		})
+		assert.Nil(t, err)
+	})
+}
+
+func TestUnorderedOptions(t *testing.T) {
+	ctx := context.Background()
+
+	// Record from the client side, so that the order of the messages
+	// depends on the client.
+	gs := grpcdump.NewServer()
+	pb.RegisterGreeterServiceServer(gs.Server, &server{shuffle: true})
+	stop := gs.ListenAndServe()
+	defer stop()
+
+	conn, err := gs.DialContext(ctx,
+		grpcdump.WithStreamInterceptor(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := pb.NewGreeterServiceClient(conn)
+
+	t.Run("unordered messages", func(t *testing.T) {
+		ctx := grpcdump.NewRecorder(t, ctx, grpcdump.UnorderedMessages("message"))
+		stream, err := client.ListGreetings(ctx, &pb.ListGreetingsRequest{
+			Count: 5,
+		})
+		assert.Nil(t, err)
+
+		for {
+			_, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+		}
+	})
+
+	t.Run("ignore interleaving", func(t *testing.T) {
+		ctx := grpcdump.NewRecorder(t, ctx, grpcdump.IgnoreInterleaving())
+		stream, err := client.Chat(ctx)
+		assert.Nil(t, err)
+
+		msgs := []string{"foo", "bar"}
+
+		// Receive the replies after sending all the messages, or one by one.
+		if rand.Intn(2) == 0 {
+			for _, msg := range msgs {
+				assert.Nil(t, stream.Send(&pb.ChatRequest{Message: msg}))
+			}
+			for range msgs {
+				_, err := stream.Recv()
+				assert.Nil(t, err)
+			}
+		} else {
+			for _, msg := range msgs {
+				assert.Nil(t, stream.Send(&pb.ChatRequest{Message: msg}))
+				_, err := stream.Recv()
+				assert.Nil(t, err)
+			}
+		}
+		stream.CloseSend()
+
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestCallOptions(t *testing.T) {
+	t.Setenv("GODEBUG", "x509sha1=1")
+	ctx := context.Background()
+	conn := grpcDialContext(t, ctx)
+	client := pb.NewGreeterServiceClient(conn)
+
+	t.Run("deadline and compressor", func(t *testing.T) {
+		ctx := grpcdump.NewRecorder(t, ctx, grpcdump.IgnoreMetadata("user-agent"))
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		_, err := client.SayHello(ctx, &pb.SayHelloRequest{
+			Name: "John Doe",
+		}, grpc.UseCompressor(gzip.Name))
+		assert.Nil(t, err)
+	})
+
+	t.Run("ignore", func(t *testing.T) {
+		ctx := grpcdump.NewRecorder(t, ctx,
+			grpcdump.IgnoreMetadata("user-agent"),
+			grpcdump.IgnoreDeadline(),
+			grpcdump.IgnoreCompressor(),
+		)
+
+		// The deadline and compressor varies on every run.
+		ctx, cancel := context.WithTimeout(ctx, time.Duration(1+rand.Intn(10))*time.Second)
+		defer cancel()
+
+		var opts []grpc.CallOption
+		if rand.Intn(2) == 0 {
+			opts = append(opts, grpc.UseCompressor(gzip.Name))
+		}
+
+		_, err := client.SayHello(ctx, &pb.SayHelloRequest{
+			Name: "John Doe",
+		}, opts...)
+		assert.Nil(t, err)
+	})
+}
+
 type server struct {
 	pb.UnimplementedGreeterServiceServer
 	dynamic bool
+	shuffle bool
 }
 
 // SayHello implements helloworld.GreeterServer
@@ -456,7 +735,18 @@ func (s *server) ListGreetings(in *pb.ListGreetingsRequest, stream pb.GreeterSer
 		return st.Err()
 	}
 
-	for i := 0; i < int(n); i++ {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	if s.shuffle {
+		// Simulate messages sent from multiple goroutines.
+		rand.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+	}
+
+	for _, i := range order {
 		err := stream.Send(&pb.ListGreetingsResponse{
 			Message: fmt.Sprintf("hi sir (%d)", i+1),
 		})
@@ -511,7 +801,7 @@ func (s *server) Chat(stream pb.GreeterService_ChatServer) error {
 	}
 }
 
-func testServerStreaming(t *testing.T, req *pb.ListGreetingsRequest) error {
+func testServerStreaming(t *testing.T, req *pb.ListGreetingsRequest, opts ...grpcdump.Option) error {
 	t.Helper()
 
 	ctx := context.Background()
@@ -521,7 +811,7 @@ func testServerStreaming(t *testing.T, req *pb.ListGreetingsRequest) error {
 	client := pb.NewGreeterServiceClient(conn)
 
 	// Create a new recorder.
-	ctx = grpcdump.NewRecorder(t, ctx, grpcdump.IgnoreMetadata("user-agent"))
+	ctx = grpcdump.NewRecorder(t, ctx, append(opts, grpcdump.IgnoreMetadata("user-agent"))...)
 
 	ctx = metadata.AppendToOutgoingContext(ctx,
 		"md-val", "md-val",
@@ -1,15 +1,20 @@
 package grpcdump
 
 import (
+	"encoding/base64"
+	"encoding/json"
+
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 // Status is a struct that represents the status of a gRPC call.
 type Status struct {
-	Code    string     `json:"code"`    // Code is the string representation of the status code.
-	Number  codes.Code `json:"number"`  // Number is the numerical representation of the status code.
-	Message string     `json:"message"` // Message is the error message associated with the status code.
+	Code    string     `json:"code"`              // Code is the string representation of the status code.
+	Number  codes.Code `json:"number"`            // Number is the numerical representation of the status code.
+	Message string     `json:"message"`           // Message is the error message associated with the status code.
+	Details []any      `json:"details,omitempty"` // Details is the protojson representation of the error details, with the `@type` URL.
 }
 
 // newStatus is a function that takes an error and returns a new Status object.
@@ -25,5 +30,35 @@ func newStatus(err error) *Status {
 		Code:    sts.Code().String(), // Convert the status code to a string.
 		Number:  sts.Code(),          // Get the numerical representation of the status code.
 		Message: sts.Message(),       // Get the error message associated with the status code.
+		Details: statusDetails(sts),  // Get the error details, e.g. errdetails.BadRequest.
+	}
+}
+
+// statusDetails converts the status details into maps, so that they can be
+// compared structurally.
+// Details with unknown types are kept as base64 encoded value.
+func statusDetails(sts *status.Status) []any {
+	details := sts.Proto().GetDetails()
+	if len(details) == 0 {
+		return nil
 	}
+
+	res := make([]any, len(details))
+	for i, d := range details {
+		var a any
+		b, err := protojson.Marshal(d)
+		if err == nil {
+			err = json.Unmarshal(b, &a)
+		}
+		if err != nil {
+			a = map[string]any{
+				"@type": d.GetTypeUrl(),
+				"value": base64.StdEncoding.EncodeToString(d.GetValue()),
+			}
+		}
+
+		res[i] = a
+	}
+
+	return res
 }
@@ -0,0 +1,228 @@
+package httpdump
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/alextanhongpin/testdump/httpdump/internal"
+)
+
+// Codec formats and decodes the body of a specific content type.
+type Codec interface {
+	// Format pretty prints the body before it is written to the snapshot.
+	Format([]byte) ([]byte, error)
+
+	// Decode converts the body into a structured value for comparison.
+	// The value should be composed of maps, slices and primitives, so that
+	// the fields can be ignored by name.
+	Decode([]byte) (any, error)
+}
+
+var codecs = NewCodecRegistry()
+
+// RegisterCodec registers the codec for the content type globally.
+func RegisterCodec(contentType string, c Codec) {
+	codecs.Register(contentType, c)
+}
+
+// CodecRegistry holds the codecs by media type.
+type CodecRegistry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}
+
+// NewCodecRegistry returns a new registry with the default codecs for JSON,
+// XML, HTML and form-urlencoded bodies.
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{
+		codecs: map[string]Codec{
+			"application/json":                  JSONCodec{},
+			"application/xml":                   XMLCodec{},
+			"text/xml":                          XMLCodec{},
+			"text/html":                         HTMLCodec{},
+			"application/x-www-form-urlencoded": FormCodec{},
+		},
+	}
+}
+
+// Register registers the codec for the content type.
+// Parameters such as charset are ignored.
+func (r *CodecRegistry) Register(contentType string, c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codecs[mediaType(contentType)] = c
+}
+
+// Get returns the codec for the content type.
+// Structured syntax suffixes such as `application/soap+xml` and
+// `application/problem+json` falls back to the XML and JSON codec.
+func (r *CodecRegistry) Get(contentType string) (Codec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	typ := mediaType(contentType)
+	if c, ok := r.codecs[typ]; ok {
+		return c, true
+	}
+
+	if i := strings.LastIndex(typ, "+"); i != -1 {
+		switch typ[i+1:] {
+		case "json":
+			c, ok := r.codecs["application/json"]
+			return c, ok
+		case "xml":
+			c, ok := r.codecs["application/xml"]
+			return c, ok
+		}
+	}
+
+	return nil, false
+}
+
+func (r *CodecRegistry) clone() *CodecRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := &CodecRegistry{
+		codecs: make(map[string]Codec, len(r.codecs)),
+	}
+	for k, v := range r.codecs {
+		c.codecs[k] = v
+	}
+
+	return c
+}
+
+// format pretty prints the body using the codec for the content type.
+// Bodies without a matching codec are indented if they are valid JSON.
+func (r *CodecRegistry) format(contentType string, b []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return b, nil
+	}
+
+	c, ok := r.Get(contentType)
+	if !ok {
+		c = JSONCodec{}
+	}
+
+	return c.Format(b)
+}
+
+// decode converts the body into a comparable value using the codec for the
+// content type.
+// Bodies without a matching codec are decoded if they are valid JSON,
+// otherwise they are compared as string.
+func (r *CodecRegistry) decode(contentType string, b []byte) (any, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return string(b), nil
+	}
+
+	c, ok := r.Get(contentType)
+	if !ok {
+		c = JSONCodec{}
+	}
+
+	return c.Decode(b)
+}
+
+func mediaType(contentType string) string {
+	typ, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	return typ
+}
+
+// JSONCodec indents the JSON body.
+// Invalid JSON are left as it is.
+type JSONCodec struct{}
+
+func (JSONCodec) Format(b []byte) ([]byte, error) {
+	return internal.PrettyBytes(b)
+}
+
+func (JSONCodec) Decode(b []byte) (any, error) {
+	if !json.Valid(b) {
+		return string(b), nil
+	}
+
+	var a any
+	if err := json.Unmarshal(b, &a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// XMLCodec canonicalizes the XML body, and compares the elements by name.
+// Attributes are prefixed with `@`.
+// Invalid XML are left as it is.
+type XMLCodec struct{}
+
+func (XMLCodec) Format(b []byte) ([]byte, error) {
+	f, err := internal.FormatXML(b)
+	if err != nil {
+		// Skip formatting, e.g. plain text error messages.
+		return b, nil
+	}
+
+	return f, nil
+}
+
+func (XMLCodec) Decode(b []byte) (any, error) {
+	a, err := internal.DecodeXML(b)
+	if err != nil {
+		return string(b), nil
+	}
+
+	return a, nil
+}
+
+// HTMLCodec normalizes the HTML body, and compares the DOM tree.
+type HTMLCodec struct{}
+
+func (HTMLCodec) Format(b []byte) ([]byte, error) {
+	return internal.FormatHTML(b)
+}
+
+func (HTMLCodec) Decode(b []byte) (any, error) {
+	return internal.DecodeHTML(b)
+}
+
+// FormCodec sorts the form-urlencoded body by key, and compares the values
+// by key.
+type FormCodec struct{}
+
+func (FormCodec) Format(b []byte) ([]byte, error) {
+	v, err := url.ParseQuery(string(bytes.TrimSpace(b)))
+	if err != nil {
+		return b, nil
+	}
+
+	return []byte(v.Encode()), nil
+}
+
+func (FormCodec) Decode(b []byte) (any, error) {
+	v, err := url.ParseQuery(string(b))
+	if err != nil {
+		return string(b), nil
+	}
+
+	m := make(map[string]any, len(v))
+	for k, vs := range v {
+		a := make([]any, len(vs))
+		for i, s := range vs {
+			a[i] = s
+		}
+		m[k] = a
+	}
+
+	return m, nil
+}
@@ -2,7 +2,6 @@ package httpdump
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,6 +14,7 @@ import (
 type comparer struct {
 	colors bool
 	cmpOpt CompareOption
+	codecs *CodecRegistry
 }
 
 func (c *comparer) Compare(a, b any) error {
@@ -37,12 +37,12 @@ func (c *comparer) compare(snapshot, received *HTTP) error {
 }
 
 func (c *comparer) compareRequest(snapshot *HTTP, received *HTTP) error {
-	s, err := NewComparableRequest(snapshot.Request, snapshot.RequestBody)
+	s, err := newComparableRequest(snapshot.Request, snapshot.RequestBody, c.codecs)
 	if err != nil {
 		return err
 	}
 
-	r, err := NewComparableRequest(received.Request, received.RequestBody)
+	r, err := newComparableRequest(received.Request, received.RequestBody, c.codecs)
 	if err != nil {
 		return err
 	}
@@ -51,12 +51,12 @@ func (c *comparer) compareRequest(snapshot *HTTP, received *HTTP) error {
 }
 
 func (c *comparer) compareResponse(snapshot, received *HTTP) error {
-	s, err := NewComparableResponse(snapshot.Response, snapshot.ResponseBody)
+	s, err := newComparableResponse(snapshot.Response, snapshot.ResponseBody, c.codecs)
 	if err != nil {
 		return err
 	}
 
-	r, err := NewComparableResponse(received.Response, received.ResponseBody)
+	r, err := newComparableResponse(received.Response, received.ResponseBody, c.codecs)
 	if err != nil {
 		return err
 	}
@@ -115,26 +115,36 @@ func (c CompareOption) Merge(o CompareOption) CompareOption {
 	}
 }
 
+// NewComparableRequest returns the comparable representation of the request.
+// The body is decoded by the codec registered for the content type.
 func NewComparableRequest(r *http.Request, body []byte) (*Message, error) {
-	var a any
-	if r.Body != nil {
-		b, err := io.ReadAll(r.Body)
+	return newComparableRequest(r, body, codecs)
+}
+
+// NewComparableResponse returns the comparable representation of the
+// response.
+// The body is decoded by the codec registered for the content type.
+func NewComparableResponse(r *http.Response, body []byte) (*Message, error) {
+	return newComparableResponse(r, body, codecs)
+}
+
+func newComparableRequest(r *http.Request, body []byte, codecs *CodecRegistry) (*Message, error) {
+	b := body
+	if b == nil && r.Body != nil {
+		var err error
+		b, err = io.ReadAll(r.Body)
 		if err != nil {
 			return nil, err
 		}
-		b = bytes.TrimSpace(b)
-
-		if json.Valid(b) {
-			if err := json.Unmarshal(b, &a); err != nil {
-				return nil, err
-			}
-		} else {
-			a = string(b)
-		}
 
 		r.Body = io.NopCloser(bytes.NewReader(b))
 	}
 
+	a, err := newComparableBody(r.Header, b, codecs)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Message{
 		Line:   internal.FormatRequestLine(r),
 		Header: r.Header.Clone(),
@@ -142,14 +152,10 @@ func NewComparableRequest(r *http.Request, body []byte) (*Message, error) {
 	}, nil
 }
 
-func NewComparableResponse(r *http.Response, body []byte) (*Message, error) {
-	var a any
-	if json.Valid(body) {
-		if err := json.Unmarshal(body, &a); err != nil {
-			return nil, err
-		}
-	} else {
-		a = string(body)
+func newComparableResponse(r *http.Response, body []byte, codecs *CodecRegistry) (*Message, error) {
+	a, err := newComparableBody(r.Header, body, codecs)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Message{
@@ -159,3 +165,17 @@ func NewComparableResponse(r *http.Response, body []byte) (*Message, error) {
 		Trailer: r.Trailer.Clone(),
 	}, nil
 }
+
+func newComparableBody(h http.Header, b []byte, codecs *CodecRegistry) (any, error) {
+	contentType := h.Get("Content-Type")
+	if boundary, ok := internal.MultipartBoundary(contentType); ok {
+		parts, err := internal.ReadParts(bytes.TrimSpace(b), boundary)
+		if err != nil {
+			return nil, err
+		}
+
+		return internal.FormParts(parts), nil
+	}
+
+	return codecs.decode(contentType, b)
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httputil"
+	"net/textproto"
 
 	"github.com/alextanhongpin/testdump/httpdump/internal"
 	"golang.org/x/tools/txtar"
@@ -14,6 +15,7 @@ import (
 type encoder struct {
 	marshalFns []Transformer
 	indentJSON bool
+	codecs     *CodecRegistry
 }
 
 func (e *encoder) Marshal(v any) ([]byte, error) {
@@ -29,7 +31,7 @@ func (e *encoder) Marshal(v any) ([]byte, error) {
 		}
 	}
 
-	return Write(hc, e.indentJSON)
+	return write(hc, e.indentJSON, e.codecs)
 }
 
 func (e *encoder) Unmarshal(b []byte) (any, error) {
@@ -37,9 +39,18 @@ func (e *encoder) Unmarshal(b []byte) (any, error) {
 }
 
 // Write writes the request/response pair to bytes.
+// When pretty is true, the body is formatted by the codec registered for the
+// content type.
 func Write(h *HTTP, pretty bool) ([]byte, error) {
+	return write(h, pretty, codecs)
+}
+
+func write(h *HTTP, pretty bool, codecs *CodecRegistry) ([]byte, error) {
 	// Format the JSON body.
 	internal.NormalizeRequest(h.Request)
+	if err := internal.NormalizeMultipart(h.Request); err != nil {
+		return nil, err
+	}
 	req, err := httputil.DumpRequest(h.Request, false)
 	if err != nil {
 		return nil, err
@@ -66,9 +77,26 @@ func Write(h *HTTP, pretty bool) ([]byte, error) {
 		return nil, err
 	}
 
+	// Decompress the body, so that the snapshot is readable.
+	reqBody, err = internal.Decompress(h.Request.Header.Get("Content-Encoding"), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err = internal.Decompress(h.Response.Header.Get("Content-Encoding"), resBody)
+	if err != nil {
+		return nil, err
+	}
+
 	if pretty {
-		reqBody = internal.MustPrettyBytes(reqBody)
-		resBody = internal.MustPrettyBytes(resBody)
+		reqBody, err = codecs.format(h.Request.Header.Get("Content-Type"), reqBody)
+		if err != nil {
+			return nil, err
+		}
+
+		resBody, err = codecs.format(h.Response.Header.Get("Content-Type"), resBody)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if len(reqBody) == 0 {
 		reqBody = append(reqBody, '\r', '\n')
@@ -76,26 +104,42 @@ func Write(h *HTTP, pretty bool) ([]byte, error) {
 		reqBody = append(reqBody, '\r', '\n', '\r', '\n')
 	}
 
+	files := []txtar.File{
+		{
+			Name: requestFile,
+			Data: req,
+		},
+		{
+			Name: requestBodyFile,
+			Data: reqBody,
+		},
+		{
+			Name: responseFile,
+			Data: res,
+		},
+		{
+			Name: responseBodyFile,
+			Data: resBody,
+		},
+	}
+
+	// Trailers are only written when present, e.g. from streaming responses.
+	if len(h.Response.Trailer) > 0 {
+		var trailer bytes.Buffer
+		if err := h.Response.Trailer.Write(&trailer); err != nil {
+			return nil, err
+		}
+		trailer.WriteString("\r\n")
+
+		files = append(files, txtar.File{
+			Name: trailerFile,
+			Data: trailer.Bytes(),
+		})
+	}
+
 	return txtar.Format(
 		&txtar.Archive{
-			Files: []txtar.File{
-				{
-					Name: requestFile,
-					Data: req,
-				},
-				{
-					Name: requestBodyFile,
-					Data: reqBody,
-				},
-				{
-					Name: responseFile,
-					Data: res,
-				},
-				{
-					Name: responseBodyFile,
-					Data: resBody,
-				},
-			},
+			Files: files,
 		},
 	), nil
 }
@@ -124,6 +168,13 @@ func Read(b []byte) (*HTTP, error) {
 			}
 		case responseBodyFile:
 			h.ResponseBody = f.Data
+		case trailerFile:
+			b := textproto.NewReader(bufio.NewReader(bytes.NewReader(f.Data)))
+			trailer, err := b.ReadMIMEHeader()
+			if err != nil {
+				return nil, err
+			}
+			h.Response.Trailer = http.Header(trailer)
 		}
 	}
 
@@ -10,11 +10,24 @@ require (
 	github.com/alextanhongpin/testdump/pkg/file v0.0.0-20250703143725-243348572c15
 	github.com/alextanhongpin/testdump/pkg/reviver v0.0.0-20250703143725-243348572c15
 	github.com/alextanhongpin/testdump/pkg/snapshot v0.0.0-20250703143725-243348572c15
+	github.com/andybalholm/brotli v1.2.6
+	github.com/getkin/kin-openapi v0.135.0
 	github.com/google/go-cmp v0.7.0
+	golang.org/x/net v0.41.0
 	golang.org/x/tools v0.34.0
 )
 
 require (
 	github.com/andybalholm/cascadia v1.3.2 // indirect
-	golang.org/x/net v0.41.0 // indirect
+	github.com/go-openapi/jsonpointer v0.21.0 // indirect
+	github.com/go-openapi/swag v0.23.0 // indirect
+	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/josharian/intern v1.0.0 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
+	github.com/oasdiff/yaml v0.0.9 // indirect
+	github.com/oasdiff/yaml3 v0.0.9 // indirect
+	github.com/perimeterx/marshmallow v1.1.5 // indirect
+	github.com/woodsbury/decimal128 v1.3.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
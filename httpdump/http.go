@@ -2,6 +2,7 @@ package httpdump
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -100,18 +101,26 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	t := h.t
 	t.Helper()
 
-	wr, ok := w.(*httptest.ResponseRecorder)
-	if !ok {
-		t.Fatal("expected *httptest.ResponseRecorder")
-	}
-
 	// Use the cloned request to avoid modifying the original request.
 	rc, err := internal.CloneRequest(r)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	h.h.ServeHTTP(wr, rc)
+	wr, ok := w.(*httptest.ResponseRecorder)
+	if ok {
+		h.h.ServeHTTP(wr, rc)
+	} else {
+		// Tee the response for other response writers, e.g. from a real
+		// server.
+		rw := newRecorder(w)
+		h.h.ServeHTTP(rw, rc)
+		if rw.hijacked {
+			return
+		}
+
+		wr = rw.rec
+	}
 
 	// Dump the request and response to the file.
 	if err := dump(t, &HTTP{Response: wr.Result(), Request: r}, h.opts...); err != nil {
@@ -127,6 +136,21 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func dump(t *testing.T, h *HTTP, opts ...Option) error {
 	opt := newOptions().apply(opts...)
 
+	// Validate the original request/response pair, before any transformation.
+	var errs []error
+	if len(opt.validators) > 0 {
+		hc, err := h.Clone()
+		if err != nil {
+			return err
+		}
+
+		for _, v := range opt.validators {
+			if err := v.Validate(hc.Response, hc.Request); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
 	path := filepath.Join("testdata", fmt.Sprintf("%s.http", filepath.Join(t.Name(), opt.file)))
 	f, err := file.New(path, opt.overwrite())
 	if err != nil {
@@ -159,7 +183,11 @@ func dump(t *testing.T, h *HTTP, opts ...Option) error {
 		}
 	}
 
-	return snapshot.Snapshot(f, opt.encoder(), opt.comparer(), h)
+	if err := snapshot.Snapshot(f, opt.encoder(), opt.comparer(), h); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
 }
 
 func extFromContentType(contentType string) (string, error) {
@@ -2,14 +2,18 @@ package httpdump_test
 
 import (
 	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -116,6 +120,115 @@ func TestForm(t *testing.T) {
 	})
 }
 
+func TestMultipartForm(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f, fh, err := r.FormFile("avatar")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"username": r.FormValue("username"),
+			"filename": fh.Filename,
+			"size":     fh.Size,
+		})
+	})
+
+	// The multipart writer generates a random boundary on every run.
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("username", "john")
+	mw.WriteField("password", "12345678")
+	mw.WriteField("nonce", time.Now().Format(time.RFC3339Nano))
+	fw, err := mw.CreateFormFile("avatar", "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte("\x89PNG\r\n\x1a\n"))
+	mw.Close()
+
+	wr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	hd := httpdump.Handler(t, h,
+		httpdump.MaskRequestFields("[REDACTED]", "password"),
+		httpdump.IgnoreRequestFields("nonce"),
+	)
+	hd.ServeHTTP(wr, r)
+
+	t.Run("original request is preserved", func(t *testing.T) {
+		want := mw.FormDataContentType()
+		got := r.Header.Get("Content-Type")
+		if want != got {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	})
+
+	t.Run("snapshot is normalized", func(t *testing.T) {
+		b, err := os.ReadFile("testdata/TestMultipartForm.http")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h, err := httpdump.Read(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The random boundary is replaced with a fixed one.
+		want := "multipart/form-data; boundary=testdump-boundary"
+		got := h.Request.Header.Get("Content-Type")
+		if want != got {
+			t.Fatalf("want %s, got %s", want, got)
+		}
+
+		// The masked fields and file parts can be read back.
+		mr := multipart.NewReader(bytes.NewReader(h.RequestBody), "testdump-boundary")
+		form, err := mr.ReadForm(1 << 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want, got := "[REDACTED]", form.Value["password"][0]; want != got {
+			t.Errorf("want %s, got %s", want, got)
+		}
+
+		fhs := form.File["avatar"]
+		if len(fhs) != 1 {
+			t.Fatalf("want 1 file, got %d", len(fhs))
+		}
+		if want, got := "avatar.png", fhs[0].Filename; want != got {
+			t.Errorf("want %s, got %s", want, got)
+		}
+
+		f, err := fhs[0].Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		content, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sum := sha256.Sum256([]byte("\x89PNG\r\n\x1a\n"))
+		want = fmt.Sprintf("sha256:%x size:8", sum)
+		if got := string(content); want != got {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	})
+}
+
 func TestJSON(t *testing.T) {
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		type request struct {
@@ -210,6 +323,38 @@ func TestJSONDynamicFields(t *testing.T) {
 	hd.ServeHTTP(wr, r)
 }
 
+func TestJSONPaths(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"user": map[string]any{
+					"id":    rand.Intn(1_000_000),
+					"token": fmt.Sprintf("token-%d", rand.Intn(1_000_000)),
+				},
+				"org": map[string]any{
+					"id": 1,
+				},
+				"sessions": []map[string]any{
+					{"id": fmt.Sprint(rand.Intn(1_000_000)), "device": "mobile"},
+					{"id": "2", "device": "desktop"},
+				},
+			},
+		})
+	}
+
+	wr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user": {"name": "John", "password": "12345678"}, "name": "john"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	hd := httpdump.HandlerFunc(t, h,
+		httpdump.MaskRequestPaths("[REDACTED]", "user.password"),
+		httpdump.MaskResponsePaths("[REDACTED]", "data.user.token"),
+		httpdump.IgnoreResponsePaths("data.user.id", "data.sessions[0].id"),
+	)
+	hd.ServeHTTP(wr, r)
+}
+
 func TestTransformer(t *testing.T) {
 	h := func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/plain")
@@ -369,6 +514,191 @@ func TestHTMLBody(t *testing.T) {
 	hd.ServeHTTP(wr, r)
 }
 
+func TestXML(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body>
+	<GetUserResponse><User id="1" status="active"><Name>John</Name>
+	<CreatedAt>%s</CreatedAt></User></GetUserResponse>
+</soap:Body></soap:Envelope>`, time.Now().Format(time.RFC3339Nano))
+	}
+
+	wr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`<GetUserRequest><Id>1</Id></GetUserRequest>`))
+	r.Header.Set("Content-Type", "text/xml")
+
+	hd := httpdump.HandlerFunc(t, h, httpdump.IgnoreResponseFields("CreatedAt"))
+	hd.ServeHTTP(wr, r)
+}
+
+func TestGzip(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+
+		gw := gzip.NewWriter(w)
+		defer gw.Close()
+
+		json.NewEncoder(gw).Encode(map[string]any{
+			"message": "hello world",
+		})
+	}
+
+	wr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	hd := httpdump.HandlerFunc(t, h)
+	hd.ServeHTTP(wr, r)
+}
+
+func TestGzipRequest(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	var body bytes.Buffer
+	gw := gzip.NewWriter(&body)
+	json.NewEncoder(gw).Encode(map[string]any{
+		"message": "hello world",
+	})
+	gw.Close()
+
+	wr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "gzip")
+
+	hd := httpdump.HandlerFunc(t, h)
+	hd.ServeHTTP(wr, r)
+}
+
+func TestUnknownEncoding(t *testing.T) {
+	// The body is kept as it is, since zstd is not decoded.
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Encoding", "zstd")
+		fmt.Fprint(w, "hello world")
+	}
+
+	wr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	hd := httpdump.HandlerFunc(t, h)
+	hd.ServeHTTP(wr, r)
+}
+
+type upperCodec struct{}
+
+func (upperCodec) Format(b []byte) ([]byte, error) {
+	return bytes.ToUpper(b), nil
+}
+
+func (upperCodec) Decode(b []byte) (any, error) {
+	return string(b), nil
+}
+
+func TestBodyCodec(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "hello world")
+	}
+
+	wr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	hd := httpdump.HandlerFunc(t, h, httpdump.BodyCodec("text/plain", upperCodec{}))
+	hd.ServeHTTP(wr, r)
+}
+
+func TestOpenAPI(t *testing.T) {
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": 1, "name": "John"}`)
+	}
+
+	wr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/users/1", nil)
+
+	hd := httpdump.HandlerFunc(t, h, httpdump.OpenAPI("testdata/openapi.yaml"))
+	hd.ServeHTTP(wr, r)
+
+	t.Run("invalid response", func(t *testing.T) {
+		v, err := httpdump.NewOpenAPIValidator("testdata/openapi.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wr := httptest.NewRecorder()
+		wr.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(wr, `{"id": "1"}`)
+
+		err = v.Validate(wr.Result(), httptest.NewRequest(http.MethodGet, "/api/users/1", nil))
+		if err == nil || !strings.Contains(err.Error(), "OpenAPI: Response") {
+			t.Fatalf("want response error, got %v", err)
+		}
+	})
+
+	t.Run("unknown route", func(t *testing.T) {
+		v, err := httpdump.NewOpenAPIValidator("testdata/openapi.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = v.Validate(httptest.NewRecorder().Result(), httptest.NewRequest(http.MethodGet, "/api/posts", nil))
+		if err == nil {
+			t.Fatal("want error, got nil")
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Trailer", "X-Checksum")
+		fmt.Fprint(w, `{"message": "hello"}`)
+		w.(http.Flusher).Flush()
+		w.Header().Set("X-Checksum", "abc")
+	})
+	mux.HandleFunc("POST /echo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.Copy(w, r.Body)
+	})
+
+	mw := httpdump.Middleware(t, httpdump.IgnoreResponseHeaders("Date"))
+	ts := httptest.NewServer(mw(mux))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("original response is preserved", func(t *testing.T) {
+		want := `{"message": "hello"}`
+		if got := string(b); want != got {
+			t.Errorf("want %s, got %s", want, got)
+		}
+		if want, got := "abc", resp.Trailer.Get("X-Checksum"); want != got {
+			t.Errorf("want trailer %s, got %s", want, got)
+		}
+	})
+
+	resp, err = http.Post(ts.URL+"/echo", "application/json", strings.NewReader(`{"name": "john"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
 func TestMask(t *testing.T) {
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		type response struct {
@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -12,3 +14,37 @@ func IgnoreMapEntries(keys ...string) cmp.Option {
 		return slices.Contains(keys, k)
 	})
 }
+
+// IgnorePaths ignores the values at the given paths.
+// The paths are dotted, with arrays indexed by `[i]`, e.g. `data.users[0].id`.
+func IgnorePaths(paths ...string) cmp.Option {
+	return cmp.FilterPath(func(p cmp.Path) bool {
+		return slices.Contains(paths, JoinPath(p))
+	}, cmp.Ignore())
+}
+
+// JoinPath formats the cmp.Path of map keys and slice indices into a dotted
+// path.
+func JoinPath(p cmp.Path) string {
+	var keys []string
+	for _, s := range p {
+		switch s := s.(type) {
+		case cmp.MapIndex:
+			keys = append(keys, fmt.Sprint(s.Key()))
+		case cmp.SliceIndex:
+			i, j := s.SplitKeys()
+			if i < 0 {
+				i = j
+			}
+
+			idx := fmt.Sprintf("[%d]", i)
+			if len(keys) == 0 {
+				keys = append(keys, idx)
+			} else {
+				keys[len(keys)-1] += idx
+			}
+		}
+	}
+
+	return strings.Join(keys, ".")
+}
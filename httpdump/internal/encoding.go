@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Decompress decodes the body based on the Content-Encoding header.
+// Multiple encodings are applied in the order they are listed, so they are
+// decoded in reverse.
+// Only gzip, deflate and br are decoded. The body is returned as it is when
+// any of the encodings is unknown, e.g. zstd.
+func Decompress(contentEncoding string, b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return b, nil
+	}
+
+	raw := b
+
+	encodings := strings.Split(contentEncoding, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var r io.Reader
+		var err error
+
+		switch strings.ToLower(strings.TrimSpace(encodings[i])) {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(bytes.NewReader(b))
+		case "deflate":
+			r, err = zlib.NewReader(bytes.NewReader(b))
+		case "br":
+			r = brotli.NewReader(bytes.NewReader(b))
+		default:
+			return raw, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		b, err = io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// FormatHTML normalizes the HTML document by sorting the attributes,
+// collapsing whitespaces and indenting the elements.
+// Comments are dropped.
+func FormatHTML(b []byte) ([]byte, error) {
+	nodes, err := parseHTML(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		writeHTML(&buf, n, 0)
+	}
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// DecodeHTML converts the HTML document into a tree of maps for comparison.
+// Each element is represented by its tag, attributes and children.
+func DecodeHTML(b []byte) (any, error) {
+	nodes, err := parseHTML(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []any
+	for _, n := range nodes {
+		if v := htmlValue(n); v != nil {
+			res = append(res, v)
+		}
+	}
+
+	return res, nil
+}
+
+// parseHTML parses a full document if it starts with a doctype or the html
+// element, otherwise as a fragment of the body, which is common for
+// partials returned by HTMX.
+func parseHTML(b []byte) ([]*html.Node, error) {
+	head := strings.ToLower(string(bytes.TrimSpace(b[:min(len(b), 64)])))
+	if strings.HasPrefix(head, "<!doctype") || strings.HasPrefix(head, "<html") {
+		doc, err := html.Parse(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+
+		var nodes []*html.Node
+		for c := doc.FirstChild; c != nil; c = c.NextSibling {
+			nodes = append(nodes, c)
+		}
+
+		return nodes, nil
+	}
+
+	return html.ParseFragment(bytes.NewReader(b), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+}
+
+func writeHTML(buf *bytes.Buffer, n *html.Node, depth int) {
+	indent := strings.Repeat(" ", depth)
+
+	switch n.Type {
+	case html.DoctypeNode:
+		buf.WriteString(indent + "<!DOCTYPE " + n.Data + ">\n")
+	case html.TextNode:
+		text := collapseSpace(n.Data)
+		if text == "" {
+			return
+		}
+		buf.WriteString(indent + html.EscapeString(text) + "\n")
+	case html.ElementNode:
+		buf.WriteString(indent + "<" + n.Data)
+		for _, a := range sortedAttrs(n.Attr) {
+			buf.WriteString(" " + attrName(a) + `="` + html.EscapeString(a.Val) + `"`)
+		}
+		buf.WriteString(">\n")
+
+		if isVoidElement(n.Data) {
+			return
+		}
+
+		if isRawElement(n.Data) {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if text := strings.TrimSpace(c.Data); text != "" {
+					buf.WriteString(text + "\n")
+				}
+			}
+		} else {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				writeHTML(buf, c, depth+1)
+			}
+		}
+
+		buf.WriteString(indent + "</" + n.Data + ">\n")
+	}
+}
+
+func htmlValue(n *html.Node) any {
+	switch n.Type {
+	case html.TextNode:
+		text := collapseSpace(n.Data)
+		if isRawElement(parentTag(n)) {
+			text = strings.TrimSpace(n.Data)
+		}
+		if text == "" {
+			return nil
+		}
+
+		return text
+	case html.ElementNode:
+		m := map[string]any{
+			"tag": n.Data,
+		}
+		if len(n.Attr) > 0 {
+			attrs := make(map[string]any)
+			for _, a := range n.Attr {
+				attrs[attrName(a)] = a.Val
+			}
+			m["attrs"] = attrs
+		}
+
+		var children []any
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if v := htmlValue(c); v != nil {
+				children = append(children, v)
+			}
+		}
+		if len(children) > 0 {
+			m["children"] = children
+		}
+
+		return m
+	default:
+		return nil
+	}
+}
+
+func sortedAttrs(attrs []html.Attribute) []html.Attribute {
+	attrs = slices.Clone(attrs)
+	slices.SortFunc(attrs, func(a, b html.Attribute) int {
+		return strings.Compare(attrName(a), attrName(b))
+	})
+
+	return attrs
+}
+
+func attrName(a html.Attribute) string {
+	if a.Namespace == "" {
+		return a.Key
+	}
+
+	return a.Namespace + ":" + a.Key
+}
+
+func parentTag(n *html.Node) string {
+	if n.Parent == nil {
+		return ""
+	}
+
+	return n.Parent.Data
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func isRawElement(tag string) bool {
+	switch tag {
+	case "pre", "script", "style", "textarea":
+		return true
+	default:
+		return false
+	}
+}
+
+func isVoidElement(tag string) bool {
+	switch tag {
+	case "area", "base", "br", "col", "embed", "hr", "img", "input", "link",
+		"meta", "source", "track", "wbr":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+// Boundary is the multipart boundary used in the snapshot, so that the
+// randomly generated boundary does not change the snapshot on every run.
+const Boundary = "testdump-boundary"
+
+// Part is a single part of a multipart/form-data body.
+type Part struct {
+	Header textproto.MIMEHeader
+	Body   []byte
+}
+
+// FormName returns the name parameter of the Content-Disposition header.
+func (p *Part) FormName() string {
+	_, params, _ := mime.ParseMediaType(p.Header.Get("Content-Disposition"))
+	return params["name"]
+}
+
+// FileName returns the filename parameter of the Content-Disposition header.
+func (p *Part) FileName() string {
+	_, params, _ := mime.ParseMediaType(p.Header.Get("Content-Disposition"))
+	return params["filename"]
+}
+
+// MultipartBoundary returns the boundary if the content type is
+// multipart/form-data.
+func MultipartBoundary(contentType string) (string, bool) {
+	typ, params, err := mime.ParseMediaType(contentType)
+	if err != nil || typ != "multipart/form-data" {
+		return "", false
+	}
+
+	boundary, ok := params["boundary"]
+	return boundary, ok && boundary != ""
+}
+
+// ReadParts reads all the parts from a multipart body.
+func ReadParts(b []byte, boundary string) ([]*Part, error) {
+	r := multipart.NewReader(bytes.NewReader(b), boundary)
+
+	var parts []*Part
+	for {
+		p, err := r.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := io.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, &Part{
+			Header: p.Header,
+			Body:   body,
+		})
+	}
+
+	return parts, nil
+}
+
+// WriteParts writes the parts into a multipart body with the given boundary.
+func WriteParts(parts []*Part, boundary string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+
+	for _, p := range parts {
+		pw, err := w.CreatePart(p.Header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pw.Write(p.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// NormalizeMultipart replaces the boundary of a multipart/form-data request
+// with a fixed one, and replaces the content of the uploaded files with their
+// hash and size.
+func NormalizeMultipart(r *http.Request) error {
+	boundary, ok := MultipartBoundary(r.Header.Get("Content-Type"))
+	if !ok || r.Body == nil {
+		return nil
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	_ = r.Body.Close()
+
+	parts, err := ReadParts(b, boundary)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range parts {
+		if p.FileName() == "" {
+			continue
+		}
+
+		p.Body = []byte(summarize(p.Body))
+	}
+
+	b, err = WriteParts(parts, Boundary)
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Content-Type", mime.FormatMediaType("multipart/form-data", map[string]string{
+		"boundary": Boundary,
+	}))
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	r.ContentLength = int64(len(b))
+
+	return nil
+}
+
+// FormParts converts the parts into a map of form name to the list of values,
+// similar to url.Values.
+// Files are represented by their file name, content type and content.
+func FormParts(parts []*Part) map[string]any {
+	m := make(map[string]any)
+	for _, p := range parts {
+		var v any = string(p.Body)
+		if name := p.FileName(); name != "" {
+			v = map[string]any{
+				"filename":     name,
+				"content_type": p.Header.Get("Content-Type"),
+				"content":      string(p.Body),
+			}
+		}
+
+		vs, _ := m[p.FormName()].([]any)
+		m[p.FormName()] = append(vs, v)
+	}
+
+	return m
+}
+
+func summarize(b []byte) string {
+	return fmt.Sprintf("sha256:%x size:%d", sha256.Sum256(b), len(b))
+}
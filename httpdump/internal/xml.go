@@ -0,0 +1,180 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+)
+
+// xmlNode is a minimal XML element tree. Comments, processing instructions
+// and directives are dropped.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	children []*xmlNode
+}
+
+// FormatXML canonicalizes the XML document by sorting the attributes,
+// trimming whitespace-only text and indenting the elements.
+func FormatXML(b []byte) ([]byte, error) {
+	root, err := parseXML(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeXML(&buf, root, 0)
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// DecodeXML converts the XML document into a map, so that the elements can
+// be compared (and ignored) by name.
+// Attributes are prefixed with `@`, and repeated elements are grouped into a
+// slice.
+func DecodeXML(b []byte) (any, error) {
+	root, err := parseXML(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		root.name: xmlValue(root),
+	}, nil
+}
+
+func parseXML(b []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	dec.Strict = false
+
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		// RawToken preserves the namespace prefix of the elements.
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			attrs := slices.Clone(t.Attr)
+			slices.SortFunc(attrs, func(a, b xml.Attr) int {
+				return strings.Compare(xmlName(a.Name), xmlName(b.Name))
+			})
+
+			n := &xmlNode{
+				name:  xmlName(t.Name),
+				attrs: attrs,
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, errors.New("xml: unexpected end element")
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) == 0 {
+				continue
+			}
+			n := stack[len(stack)-1]
+			n.text += string(t)
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("xml: missing root element")
+	}
+
+	return root, nil
+}
+
+func writeXML(buf *bytes.Buffer, n *xmlNode, depth int) {
+	indent := strings.Repeat(" ", depth)
+	text := strings.TrimSpace(n.text)
+
+	buf.WriteString(indent)
+	buf.WriteString("<")
+	buf.WriteString(n.name)
+	for _, a := range n.attrs {
+		buf.WriteString(" ")
+		buf.WriteString(xmlName(a.Name))
+		buf.WriteString(`="`)
+		_ = xml.EscapeText(buf, []byte(a.Value))
+		buf.WriteString(`"`)
+	}
+
+	if text == "" && len(n.children) == 0 {
+		buf.WriteString("/>\n")
+		return
+	}
+	buf.WriteString(">")
+
+	if len(n.children) == 0 {
+		_ = xml.EscapeText(buf, []byte(text))
+	} else {
+		buf.WriteString("\n")
+		if text != "" {
+			buf.WriteString(indent + " ")
+			_ = xml.EscapeText(buf, []byte(text))
+			buf.WriteString("\n")
+		}
+		for _, c := range n.children {
+			writeXML(buf, c, depth+1)
+		}
+		buf.WriteString(indent)
+	}
+
+	buf.WriteString("</")
+	buf.WriteString(n.name)
+	buf.WriteString(">\n")
+}
+
+func xmlValue(n *xmlNode) any {
+	text := strings.TrimSpace(n.text)
+	if len(n.attrs) == 0 && len(n.children) == 0 {
+		return text
+	}
+
+	m := make(map[string]any)
+	for _, a := range n.attrs {
+		m["@"+xmlName(a.Name)] = a.Value
+	}
+	if text != "" {
+		m["#text"] = text
+	}
+	for _, c := range n.children {
+		v := xmlValue(c)
+		switch prev := m[c.name].(type) {
+		case nil:
+			m[c.name] = v
+		case []any:
+			m[c.name] = append(prev, v)
+		default:
+			m[c.name] = []any{prev, v}
+		}
+	}
+
+	return m
+}
+
+func xmlName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+
+	return n.Space + ":" + n.Local
+}
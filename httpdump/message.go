@@ -12,6 +12,7 @@ const (
 	requestBodyFile  = "request_body.http"
 	responseFile     = "response.http"
 	responseBodyFile = "response_body.http"
+	trailerFile      = "trailer.http"
 )
 
 type HTTP struct {
@@ -31,6 +32,7 @@ func (h *HTTP) Clone() (*HTTP, error) {
 	if err != nil {
 		return nil, err
 	}
+	w.Trailer = h.Response.Trailer.Clone()
 
 	return &HTTP{
 		Request:      r,
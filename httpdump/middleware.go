@@ -0,0 +1,129 @@
+package httpdump
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alextanhongpin/testdump/httpdump/internal"
+)
+
+// Middleware is a function that takes a testing object and a variadic list of options.
+// It returns a middleware that dumps every request served by the next handler.
+func Middleware(t *testing.T, opts ...Option) func(http.Handler) http.Handler {
+	return d.Middleware(t, opts...)
+}
+
+// Middleware is a method on the Dumper struct that returns a middleware that
+// dumps every request served by the next handler.
+// Unlike Handler, the response writer can be any http.ResponseWriter, so it
+// can be used with a real listener, e.g. httptest.Server or http.Server in
+// e2e tests.
+// Since there can be many requests, the snapshots are numbered in the order
+// the requests are completed, e.g. `testdata/TestName/request#1.http`, or
+// `testdata/TestName/<file>#1.http` when the File option is used.
+func (d *Dumper) Middleware(t *testing.T, opts ...Option) func(http.Handler) http.Handler {
+	opts = append(d.opts, opts...)
+
+	var n atomic.Int64
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rc, err := internal.CloneRequest(r)
+			if err != nil {
+				t.Error(err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rw := newRecorder(w)
+			next.ServeHTTP(rw, rc)
+
+			// The response is written directly to the connection.
+			if rw.hijacked {
+				return
+			}
+
+			i := n.Add(1)
+			opts := append(opts, func(o *options) {
+				o.file = fmt.Sprintf("%s#%d", cmp.Or(o.file, "request"), i)
+			})
+			if err := dump(t, &HTTP{Response: rw.rec.Result(), Request: r}, opts...); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// recorder tees the response to the underlying response writer and a
+// httptest.ResponseRecorder.
+type recorder struct {
+	w        http.ResponseWriter
+	rec      *httptest.ResponseRecorder
+	hijacked bool
+}
+
+func newRecorder(w http.ResponseWriter) *recorder {
+	rec := httptest.NewRecorder()
+	// Share the headers, so that the trailers set after the body is written
+	// are recorded too.
+	rec.HeaderMap = w.Header()
+
+	return &recorder{
+		w:   w,
+		rec: rec,
+	}
+}
+
+func (r *recorder) Header() http.Header {
+	return r.w.Header()
+}
+
+func (r *recorder) WriteHeader(code int) {
+	r.rec.WriteHeader(code)
+	r.w.WriteHeader(code)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	// Take the snapshot of the header before it is written.
+	r.rec.WriteHeader(http.StatusOK)
+
+	n, err := r.w.Write(b)
+	_, _ = r.rec.Write(b[:n])
+
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (r *recorder) Flush() {
+	r.rec.Flush()
+	if f, ok := r.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+// Hijacked connections are not dumped.
+func (r *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("httpdump: response writer does not implement http.Hijacker")
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		r.hijacked = true
+	}
+
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying response writer for http.ResponseController.
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.w
+}
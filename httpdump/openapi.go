@@ -0,0 +1,183 @@
+package httpdump
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// Validator validates the request/response pair before it is written to the
+// snapshot.
+type Validator interface {
+	Validate(w *http.Response, r *http.Request) error
+}
+
+// lazyValidator loads the validator on the first validation.
+type lazyValidator struct {
+	once sync.Once
+	load func() (Validator, error)
+	v    Validator
+	err  error
+}
+
+func (l *lazyValidator) Validate(w *http.Response, r *http.Request) error {
+	l.once.Do(func() {
+		l.v, l.err = l.load()
+	})
+	if l.err != nil {
+		return l.err
+	}
+
+	return l.v.Validate(w, r)
+}
+
+// OpenAPIValidator validates the request/response pair against an OpenAPI 3
+// document.
+// The path, method, parameters, status code, headers and body schema are
+// validated.
+type OpenAPIValidator struct {
+	router routers.Router
+}
+
+// NewOpenAPIValidator loads the OpenAPI 3 document from the file path.
+// The host of the servers are ignored, so that requests to any host, e.g.
+// httptest.Server, matches the document.
+func NewOpenAPIValidator(path string) (*OpenAPIValidator, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+
+	doc, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOpenAPIValidator(loader.Context, doc)
+}
+
+// NewOpenAPIValidatorFromData loads the OpenAPI 3 document from bytes.
+func NewOpenAPIValidatorFromData(b []byte) (*OpenAPIValidator, error) {
+	loader := openapi3.NewLoader()
+
+	doc, err := loader.LoadFromData(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOpenAPIValidator(loader.Context, doc)
+}
+
+func newOpenAPIValidator(ctx context.Context, doc *openapi3.T) (*OpenAPIValidator, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := doc.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	// Keep only the base path of the servers.
+	servers := openapi3.Servers{{URL: "/"}}
+	for _, s := range doc.Servers {
+		u, err := url.Parse(s.URL)
+		if err != nil || u.Path == "" || u.Path == "/" {
+			continue
+		}
+
+		servers = append(servers, &openapi3.Server{URL: u.Path})
+	}
+	doc.Servers = servers
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenAPIValidator{
+		router: router,
+	}, nil
+}
+
+// Validate validates the request and response against the OpenAPI document.
+// The request and response body are preserved.
+func (v *OpenAPIValidator) Validate(w *http.Response, r *http.Request) error {
+	ctx := r.Context()
+
+	req := r.Clone(ctx)
+	if req.URL.Host == "" {
+		req.URL.Host = r.Host
+	}
+	if req.URL.Scheme == "" {
+		req.URL.Scheme = "http"
+	}
+
+	route, params, err := v.router.FindRoute(req)
+	if err != nil {
+		return fmt.Errorf("OpenAPI: %s %s: %w", r.Method, r.URL.Path, err)
+	}
+
+	reqBody, err := readBody(&r.Body)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(reqBody))
+
+	opts := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+
+	in := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: params,
+		Route:      route,
+		Options:    opts,
+	}
+	if err := openapi3filter.ValidateRequest(ctx, in); err != nil {
+		return fmt.Errorf("OpenAPI: Request: %w", err)
+	}
+
+	resBody, err := readBody(&w.Body)
+	if err != nil {
+		return err
+	}
+
+	out := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: in,
+		Status:                 w.StatusCode,
+		Header:                 w.Header,
+		Body:                   io.NopCloser(bytes.NewReader(resBody)),
+		Options:                opts,
+	}
+	if err := openapi3filter.ValidateResponse(ctx, out); err != nil {
+		return fmt.Errorf("OpenAPI: Response: %w", err)
+	}
+
+	return nil
+}
+
+// readBody reads the body and replaces it with a new reader, so that it can
+// be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(*body)
+	if err != nil {
+		return nil, err
+	}
+	_ = (*body).Close()
+
+	*body = io.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
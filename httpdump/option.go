@@ -18,6 +18,8 @@ type options struct {
 	indentJSON bool
 	colors     bool
 	body       bool
+	codecs     *CodecRegistry
+	validators []Validator
 }
 
 // newOptions is a function that takes a variadic list of options and returns a new options instance with these options.
@@ -25,6 +27,7 @@ func newOptions() *options {
 	return &options{
 		indentJSON: true,
 		colors:     true,
+		codecs:     codecs,
 	}
 }
 
@@ -44,6 +47,7 @@ func (o *options) encoder() *encoder {
 	return &encoder{
 		marshalFns: o.transformers,
 		indentJSON: o.indentJSON,
+		codecs:     o.codecs,
 	}
 }
 
@@ -51,6 +55,7 @@ func (o *options) comparer() *comparer {
 	return &comparer{
 		cmpOpt: o.cmpOpt,
 		colors: o.colors,
+		codecs: o.codecs,
 	}
 }
 
@@ -92,6 +97,35 @@ func Body(body bool) Option {
 	}
 }
 
+// BodyCodec is a function that takes a content type and a codec and returns an options that formats and compares the body of the content type with the codec.
+// Unlike RegisterCodec, the codec only applies to the current dump.
+func BodyCodec(contentType string, c Codec) Option {
+	return func(o *options) {
+		o.codecs = o.codecs.clone()
+		o.codecs.Register(contentType, c)
+	}
+}
+
+// Validate is a function that takes a variadic list of validators and returns an options that validates the request/response pair before snapshotting.
+// The validation errors are reported together with the snapshot diff.
+func Validate(vs ...Validator) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, vs...)
+	}
+}
+
+// OpenAPI is a function that takes the path to an OpenAPI 3 document and returns an options that validates the request/response pair against the document.
+// The document is loaded once, on the first dump.
+func OpenAPI(path string) Option {
+	v := &lazyValidator{
+		load: func() (Validator, error) {
+			return NewOpenAPIValidator(path)
+		},
+	}
+
+	return Validate(v)
+}
+
 // Colors is a function that takes a boolean and returns an options that sets the colors field of an options instance to the given boolean.
 func Colors(colors bool) Option {
 	return func(o *options) {
@@ -127,6 +161,21 @@ func IgnoreResponseFields(val ...string) Option {
 	}
 }
 
+// IgnoreRequestPaths is similar to IgnoreRequestFields, but it ignores the values by the full path instead of the field name at any depth.
+// The paths are dotted, with arrays indexed by `[i]`, e.g. `data.users[0].id`.
+func IgnoreRequestPaths(paths ...string) Option {
+	return func(o *options) {
+		o.cmpOpt.Request.Body = append(o.cmpOpt.Request.Body, internal.IgnorePaths(paths...))
+	}
+}
+
+// IgnoreResponsePaths is similar to IgnoreRequestPaths, but it appends the paths to the Response.Body field of the cmpOpt field of an options instance.
+func IgnoreResponsePaths(paths ...string) Option {
+	return func(o *options) {
+		o.cmpOpt.Response.Body = append(o.cmpOpt.Response.Body, internal.IgnorePaths(paths...))
+	}
+}
+
 // Transformers is a function that takes a variadic list of transformers and returns an options that appends the transformers to the transformers field of an options instance.
 func Transformers(ts ...Transformer) Option {
 	return func(o *options) {
@@ -162,3 +211,18 @@ func MaskResponseFields(mask string, fields ...string) Option {
 		o.transformers = append(o.transformers, maskResponseFields(mask, fields...))
 	}
 }
+
+// MaskRequestPaths is similar to MaskRequestFields, but the new transformer masks the request fields by the full path.
+// The paths are dotted, with arrays indexed by `[i]`, e.g. `data.users[0].id`.
+func MaskRequestPaths(mask string, paths ...string) Option {
+	return func(o *options) {
+		o.transformers = append(o.transformers, maskRequestPaths(mask, paths...))
+	}
+}
+
+// MaskResponsePaths is similar to MaskRequestPaths, but the new transformer masks the response fields.
+func MaskResponsePaths(mask string, paths ...string) Option {
+	return func(o *options) {
+		o.transformers = append(o.transformers, maskResponsePaths(mask, paths...))
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/alextanhongpin/testdump/httpdump/internal"
 	"github.com/alextanhongpin/testdump/pkg/reviver"
 )
 
@@ -56,6 +57,15 @@ func maskRequestFields(mask string, fields ...string) Transformer {
 
 		// This is only available for request, since only
 		// request can contain form data.
+		if boundary, ok := internal.MultipartBoundary(r.Header.Get("Content-Type")); ok {
+			b, err := maskParts(b, boundary, mask, fields...)
+			if err != nil {
+				return err
+			}
+			r.Body = io.NopCloser(bytes.NewReader(b))
+			return nil
+		}
+
 		if !json.Valid(b) {
 			// Could this be a form data?
 			v, err := url.ParseQuery(string(b))
@@ -74,25 +84,7 @@ func maskRequestFields(mask string, fields ...string) Transformer {
 			return nil
 		}
 
-		var t map[string]any
-		if err := reviver.Unmarshal(b, &t, func(paths []string, val any) (any, error) {
-			var field string
-			if len(paths) > 0 {
-				field = paths[len(paths)-1]
-			}
-			if slices.Contains(fields, field) {
-				// Only mask the value if it is a string.
-				if _, ok := val.(string); ok {
-					return mask, nil
-				}
-			}
-
-			return val, nil
-		}); err != nil {
-			return err
-		}
-
-		b, err = json.Marshal(t)
+		b, err = maskJSON(b, maskFieldsFunc(mask, fields...))
 		if err != nil {
 			return err
 		}
@@ -115,25 +107,53 @@ func maskResponseFields(mask string, fields ...string) Transformer {
 			return nil
 		}
 
-		var t map[string]any
-		if err := reviver.Unmarshal(b, &t, func(paths []string, val any) (any, error) {
-			var field string
-			if len(paths) > 0 {
-				field = paths[len(paths)-1]
-			}
-			if slices.Contains(fields, field) {
-				// Only mask the value if it is a string.
-				if _, ok := val.(string); ok {
-					return mask, nil
-				}
-			}
+		b, err = maskJSON(b, maskFieldsFunc(mask, fields...))
+		if err != nil {
+			return err
+		}
+		w.Body = io.NopCloser(bytes.NewReader(b))
+
+		return nil
+	}
+}
+
+func maskRequestPaths(mask string, paths ...string) Transformer {
+	return func(w *http.Response, r *http.Request) error {
+		defer r.Body.Close()
+
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if !json.Valid(b) {
+			r.Body = io.NopCloser(bytes.NewReader(b))
+			return nil
+		}
+
+		b, err = maskJSON(b, maskPathsFunc(mask, paths...))
+		if err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(b))
+
+		return nil
+	}
+}
+
+func maskResponsePaths(mask string, paths ...string) Transformer {
+	return func(w *http.Response, r *http.Request) error {
+		defer w.Body.Close()
 
-			return val, nil
-		}); err != nil {
+		b, err := io.ReadAll(w.Body)
+		if err != nil {
 			return err
 		}
+		if !json.Valid(b) {
+			w.Body = io.NopCloser(bytes.NewReader(b))
+			return nil
+		}
 
-		b, err = json.Marshal(t)
+		b, err = maskJSON(b, maskPathsFunc(mask, paths...))
 		if err != nil {
 			return err
 		}
@@ -142,3 +162,74 @@ func maskResponseFields(mask string, fields ...string) Transformer {
 		return nil
 	}
 }
+
+type maskFunc = func(keys []string, val any) (any, error)
+
+// maskFieldsFunc masks the string values by the last key of the path, at
+// any depth.
+func maskFieldsFunc(mask string, fields ...string) maskFunc {
+	return func(keys []string, val any) (any, error) {
+		var field string
+		if len(keys) > 0 {
+			field = keys[len(keys)-1]
+		}
+		if slices.Contains(fields, field) {
+			// Only mask the value if it is a string.
+			if _, ok := val.(string); ok {
+				return mask, nil
+			}
+		}
+
+		return val, nil
+	}
+}
+
+// maskPathsFunc masks the string values by the full dotted path, e.g.
+// `data.users[0].id`.
+func maskPathsFunc(mask string, paths ...string) maskFunc {
+	return func(keys []string, val any) (any, error) {
+		if slices.Contains(paths, strings.Join(keys, ".")) {
+			// Only mask the value if it is a string.
+			if _, ok := val.(string); ok {
+				return mask, nil
+			}
+		}
+
+		return val, nil
+	}
+}
+
+func maskJSON(b []byte, fn maskFunc) ([]byte, error) {
+	var t any
+	if err := reviver.Unmarshal(b, &t, fn); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(t)
+}
+
+// maskParts masks the value of the form fields, or the content of the
+// uploaded files, in a multipart/form-data body.
+func maskParts(b []byte, boundary, mask string, fields ...string) ([]byte, error) {
+	parts, err := internal.ReadParts(b, boundary)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		var found bool
+		for _, p := range parts {
+			if p.FormName() != f {
+				continue
+			}
+
+			p.Body = []byte(mask)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("missing field %s", f)
+		}
+	}
+
+	return internal.WriteParts(parts, boundary)
+}
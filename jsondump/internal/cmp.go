@@ -1,7 +1,11 @@
 package internal
 
 import (
+	"encoding/json"
+	"math"
+	"math/big"
 	"slices"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -12,3 +16,61 @@ func IgnoreMapEntries(keys ...string) cmp.Option {
 		return slices.Contains(keys, k)
 	})
 }
+
+// ApproxFloats equates the numbers within the epsilon, when either number is
+// a float, e.g. 0.1 and 0.10000001.
+// The integers are still compared exactly, e.g. the large int64 IDs.
+func ApproxFloats(epsilon float64) cmp.Option {
+	return cmp.FilterValues(func(x, y json.Number) bool {
+		return isFloat(x) || isFloat(y)
+	}, cmp.Comparer(func(x, y json.Number) bool {
+		fx, errx := x.Float64()
+		fy, erry := y.Float64()
+		if errx != nil || erry != nil {
+			return x == y
+		}
+
+		return math.Abs(fx-fy) <= epsilon
+	}))
+}
+
+func isFloat(n json.Number) bool {
+	return strings.ContainsAny(string(n), ".eE")
+}
+
+// EquateNumericStrings equates the strings and the numbers with the same
+// value, e.g. "1" and 1, or "1.50" and 1.5.
+func EquateNumericStrings() cmp.Option {
+	return cmp.FilterValues(func(x, y any) bool {
+		_, _, ok := numericString(x, y)
+		return ok
+	}, cmp.Comparer(func(x, y any) bool {
+		s, n, _ := numericString(x, y)
+
+		a, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return false
+		}
+		b, ok := new(big.Rat).SetString(string(n))
+		if !ok {
+			return false
+		}
+
+		return a.Cmp(b) == 0
+	}))
+}
+
+// numericString returns the string and the number, when one is a string and
+// the other is a number.
+func numericString(x, y any) (string, json.Number, bool) {
+	if s, ok := x.(string); ok {
+		n, ok := y.(json.Number)
+		return s, n, ok
+	}
+	if s, ok := y.(string); ok {
+		n, ok := x.(json.Number)
+		return s, n, ok
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,73 @@
+package internal_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/alextanhongpin/testdump/jsondump/internal"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApproxFloats(t *testing.T) {
+	opt := internal.ApproxFloats(1e-9)
+
+	testcases := []struct {
+		name string
+		x, y string
+		want bool
+	}{
+		{"within epsilon", `{"total": 0.30000000000000004}`, `{"total": 0.3}`, true},
+		{"outside epsilon", `{"total": 0.31}`, `{"total": 0.3}`, false},
+		{"float and int", `{"total": 1.0}`, `{"total": 1}`, true},
+		{"large ints are exact", `{"id": 9007199254740993}`, `{"id": 9007199254740992}`, false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, cmp.Equal(decode(t, tc.x), decode(t, tc.y), opt))
+		})
+	}
+}
+
+func TestEquateNumericStrings(t *testing.T) {
+	opt := internal.EquateNumericStrings()
+
+	testcases := []struct {
+		name string
+		x, y string
+		want bool
+	}{
+		{"int", `{"id": "123"}`, `{"id": 123}`, true},
+		{"float", `{"price": "1.50"}`, `{"price": 1.5}`, true},
+		{"different value", `{"id": "124"}`, `{"id": 123}`, false},
+		{"not numeric", `{"id": "abc"}`, `{"id": 123}`, false},
+		{"strings are exact", `{"id": "1.0"}`, `{"id": "1"}`, false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, cmp.Equal(decode(t, tc.x), decode(t, tc.y), opt))
+		})
+	}
+}
+
+func TestExactNumbers(t *testing.T) {
+	// Without the options, 1 and 1.0 are different.
+	assert.False(t, cmp.Equal(decode(t, `{"total": 1.0}`), decode(t, `{"total": 1}`)))
+}
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+
+	var a any
+	if err := dec.Decode(&a); err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"slices"
@@ -31,18 +32,15 @@ func LoadMapValues(a any, paths ...string) map[string]any {
 	return res
 }
 
+// DeleteMapValues sets the values at the paths to nil.
+// The numbers are decoded as json.Number, so that the large integers do not
+// lose precision.
 func DeleteMapValues(a any, paths ...string) (any, error) {
 	if len(paths) == 0 {
 		return a, nil
 	}
 
-	b, err := json.Marshal(a)
-	if err != nil {
-		return nil, err
-	}
-
-	var c any
-	err = reviver.Unmarshal(b, &c, func(keys []string, v any) (any, error) {
+	b, err := reviver.Marshal(a, func(keys []string, v any) (any, error) {
 		k := strings.Join(keys, ".")
 		if slices.Contains(paths, k) {
 			return nil, nil
@@ -50,5 +48,17 @@ func DeleteMapValues(a any, paths ...string) (any, error) {
 
 		return v, nil
 	})
-	return c, err
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var c any
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
 }
@@ -133,3 +133,17 @@ func TestDeleteMapValues(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteMapValuesNumbers(t *testing.T) {
+	a := map[string]any{
+		"id":        json.Number("4611686018427387905"),
+		"createdAt": "2006-01-02T15:04:05Z",
+	}
+
+	b, err := internal.DeleteMapValues(a, "createdAt")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"id":        json.Number("4611686018427387905"),
+		"createdAt": nil,
+	}, b)
+}
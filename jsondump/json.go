@@ -4,7 +4,9 @@ import (
 	"bytes"
 	gocmp "cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -135,9 +137,25 @@ func (e *encoder) Unmarshal(b []byte) (a any, err error) {
 		b = re.ReplaceAll(b, []byte(`[IGNORE]`))
 	}
 
-	err = json.Unmarshal(b, &a)
+	return decodeJSON(b)
+}
+
+// decodeJSON decodes the numbers as json.Number, so that the numbers are
+// compared exactly, e.g. the large int64 IDs, and 1 and 1.0 are different.
+func decodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var a any
+	if err := dec.Decode(&a); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("jsondump: invalid data after top-level value")
+	}
 
-	return
+	return a, nil
 }
 
 type comparer struct {
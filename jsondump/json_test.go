@@ -375,3 +375,35 @@ func TestComparer_IgnorePatterns(t *testing.T) {
 		jsondump.Dump(t, u, jsondump.IgnorePatterns(jsondump.UUIDPattern))
 	})
 }
+
+func TestNumbers(t *testing.T) {
+	type Order struct {
+		ID    int64
+		Total float64
+	}
+
+	t.Run("large int", func(t *testing.T) {
+		// Exceeds the precision of float64.
+		jsondump.Dump(t, Order{ID: 1<<62 + 1, Total: 1})
+	})
+
+	t.Run("approx floats", func(t *testing.T) {
+		a, b := 0.1, 0.2
+		jsondump.Dump(t, Order{ID: 1, Total: a + b})
+		jsondump.Dump(t, Order{ID: 1, Total: 0.3}, jsondump.ApproxFloats(1e-9))
+	})
+
+	t.Run("ignore paths", func(t *testing.T) {
+		// The numbers are kept exact after the paths are deleted, so the
+		// options that applies to numbers still work.
+		a, b := 0.1, 0.2
+		opts := []jsondump.Option{jsondump.File("order"), jsondump.IgnorePaths("CreatedAt")}
+		jsondump.Dump(t, map[string]any{"ID": 1<<62 + 1, "Total": a + b, "CreatedAt": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, opts...)
+		jsondump.Dump(t, map[string]any{"ID": 1<<62 + 1, "Total": 0.3, "CreatedAt": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, append(opts, jsondump.ApproxFloats(1e-9))...)
+	})
+
+	t.Run("numeric strings", func(t *testing.T) {
+		jsondump.Dump(t, map[string]any{"id": 123}, jsondump.File("order"))
+		jsondump.Dump(t, map[string]any{"id": "123"}, jsondump.File("order"), jsondump.EquateNumericStrings())
+	})
+}
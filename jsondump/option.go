@@ -121,6 +121,24 @@ func CmpOpts(opts ...cmp.Option) Option {
 	}
 }
 
+// ApproxFloats is an Option that equates the floats within the epsilon, e.g.
+// the results of floating point arithmetic.
+// The integers are still compared exactly.
+func ApproxFloats(epsilon float64) Option {
+	return func(o *options) {
+		o.cmpOpts = append(o.cmpOpts, internal.ApproxFloats(epsilon))
+	}
+}
+
+// EquateNumericStrings is an Option that equates the numeric strings and the
+// numbers with the same value, e.g. "1" and 1, such as the int64 encoded as
+// string with the `json:",string"` tag.
+func EquateNumericStrings() Option {
+	return func(o *options) {
+		o.cmpOpts = append(o.cmpOpts, internal.EquateNumericStrings())
+	}
+}
+
 // IgnoreFields is an Option that ignores certain fields
 func IgnoreFields(fields ...string) Option {
 	return func(o *options) {
@@ -5,14 +5,13 @@ import (
 	"fmt"
 
 	"github.com/alextanhongpin/testdump/pkg/diff"
+	"github.com/alextanhongpin/testdump/pkg/sqlrecorder"
 	"github.com/google/go-cmp/cmp"
 	"vitess.io/vitess/go/vt/sqlparser"
 )
 
-type SQL struct {
-	Query string
-	Args  []any
-}
+// SQL is the query and args of a single call.
+type SQL = sqlrecorder.SQL
 
 type comparer struct {
 	opts   []cmp.Option
@@ -63,25 +62,80 @@ func (c *comparer) compare(snapshot, received *SQL) error {
 		return fmt.Errorf("Args: %w", err)
 	}
 
+	if err := comparer(snapshot.Plan, received.Plan); err != nil {
+		return fmt.Errorf("Plan: %w", err)
+	}
+
 	return nil
 }
 
-// CompareQuery checks if two queries are equal.
+// CompareQuery checks if two queries are equal, ignoring variables.
+// The inline literals and the bind variables are treated the same, e.g.
+// `id = 1` matches `id = ?`, and `id IN (1, 2)` matches `id IN (?)`.
 func CompareQuery(a, b string) (bool, error) {
-	parser := sqlparser.NewTestParser()
-	// To ignore variables, we can redact the statement.
-	// parser.RedactSQLQuery(a)
-	return parser.QueryMatchesTemplates(a, []string{b})
+	fa, err := fingerprintQuery(a)
+	if err != nil {
+		return false, err
+	}
+	fb, err := fingerprintQuery(b)
+	if err != nil {
+		return false, err
+	}
+
+	return fa == fb, nil
+}
+
+// fingerprintQuery returns the query with the literals and the bind
+// variables replaced with `:v`, and the IN lists replaced with `::v`.
+func fingerprintQuery(query string) (string, error) {
+	stmt, err := parser.Parse(query)
+	if err != nil {
+		return "", err
+	}
+
+	stmt = sqlparser.Rewrite(stmt, func(c *sqlparser.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *sqlparser.ComparisonExpr:
+			if n.Operator != sqlparser.InOp && n.Operator != sqlparser.NotInOp {
+				return true
+			}
+
+			switch r := n.Right.(type) {
+			case sqlparser.ValTuple:
+				if isValues(r) {
+					n.Right = sqlparser.NewListArg("v")
+				}
+			case sqlparser.ListArg:
+				n.Right = sqlparser.NewListArg("v")
+			}
+		case *sqlparser.Literal, *sqlparser.Argument:
+			c.Replace(sqlparser.NewArgument("v"))
+		}
+
+		return true
+	}, nil).(sqlparser.Statement)
+
+	return sqlparser.String(stmt), nil
+}
+
+// isValues checks if the tuple only contains literals and bind variables.
+func isValues(t sqlparser.ValTuple) bool {
+	for _, e := range t {
+		switch e.(type) {
+		case *sqlparser.Literal, *sqlparser.Argument:
+		default:
+			return false
+		}
+	}
+
+	return true
 }
 
 // toMap converts the slice args into a map for better diff.
 // Each key is named `:vn`, where `n` indicates the index of the arg in the
 // slice.
 func toMap(s []any) (any, error) {
-	m := make(map[string]any)
-	for k, v := range s {
-		m[fmt.Sprintf(":v%d", k+1)] = v
-	}
+	m := argsMap(s)
 
 	// Marshal/unmarshal to avoid type issues such as
 	// int/float.
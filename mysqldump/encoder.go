@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
 	"golang.org/x/tools/txtar"
+	"vitess.io/vitess/go/mysql/config"
 	"vitess.io/vitess/go/vt/sqlparser"
 )
 
@@ -39,24 +41,13 @@ func Read(b []byte) (*SQL, error) {
 		case querySection:
 			d.Query = string(data)
 		case argsSection:
-			var a any
-			if err := json.Unmarshal(data, &a); err != nil {
+			args, err := readArgs(data)
+			if err != nil {
 				return nil, err
 			}
-
-			m, ok := a.(map[string]any)
-			if ok {
-				// Sort to ensure the order is consistent.
-				d.Args = make([]any, len(m))
-				for k, v := range m {
-					key := strings.ReplaceAll(k, ":v", "")
-					i, err := strconv.Atoi(key) // Index starts at 1
-					if err != nil {
-						return nil, err
-					}
-					d.Args[i-1] = v
-				}
-			}
+			d.Args = args
+		case planSection:
+			d.Plan = string(data)
 		}
 	}
 
@@ -76,19 +67,9 @@ func Write(sql *SQL, transformers ...func(*SQL) error) ([]byte, error) {
 		}
 	}
 
-	// Convert args into key-value pairs.
-	// Each key is :v1, :v2, :vn ...
 	var a []byte
 	if len(sql.Args) > 0 {
-		args := make(map[string]any)
-		for i, v := range sql.Args {
-			// sqlparser replaces all '?' with ':v1', ':v2', ':vn'
-			// ...
-			k := fmt.Sprintf(":v%d", i+1)
-			args[k] = v
-		}
-
-		a, err = json.MarshalIndent(args, "", " ")
+		a, err = json.MarshalIndent(argsMap(sql.Args), "", " ")
 		if err != nil {
 			return nil, err
 		}
@@ -109,17 +90,85 @@ func Write(sql *SQL, transformers ...func(*SQL) error) ([]byte, error) {
 		})
 	}
 
+	// Plan.
+	if sql.Plan != "" {
+		arc.Files = append(arc.Files, txtar.File{
+			Name: planSection,
+			Data: appendNewLine([]byte(sql.Plan)),
+		})
+	}
+
 	return txtar.Format(arc), nil
 }
 
+// argsMap converts the args into key-value pairs.
+// sqlparser replaces all '?' with ':v1', ':v2', ':vn' ..., so each key is
+// named `:vn`, where `n` indicates the index of the arg in the slice.
+func argsMap(args []any) map[string]any {
+	m := make(map[string]any)
+	for i, v := range args {
+		m[fmt.Sprintf(":v%d", i+1)] = v
+	}
+
+	return m
+}
+
+// readArgs reads the args map written by Write back into a slice.
+func readArgs(data []byte) ([]any, error) {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	args := make([]any, len(m))
+	for k, v := range m {
+		i, err := strconv.Atoi(strings.TrimPrefix(k, ":v")) // Index starts at 1
+		if err != nil {
+			return nil, err
+		}
+		if i < 1 || i > len(args) {
+			return nil, fmt.Errorf("mysqldump: invalid arg %s", k)
+		}
+		args[i-1] = v
+	}
+
+	return args, nil
+}
+
+// maskArgs replaces the values of the args with the keys with the mask.
+func maskArgs(args []any, mask string, keys ...string) []any {
+	res := make([]any, len(args))
+	for i, v := range args {
+		if slices.Contains(keys, fmt.Sprintf(":v%d", i+1)) {
+			v = mask
+		}
+		res[i] = v
+	}
+
+	return res
+}
+
 func appendNewLine(b []byte) []byte {
 	b = append(b, '\n')
 	b = append(b, '\n')
 	return b
 }
 
+// parser is shared by the calls, since it only holds the configuration.
+var parser *sqlparser.Parser
+
+func init() {
+	var err error
+	parser, err = sqlparser.New(sqlparser.Options{
+		MySQLServerVersion: config.DefaultMySQLVersion,
+		TruncateUILen:      512,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
 func normalize(q string) (string, error) {
-	parser := sqlparser.NewTestParser()
 	stmt, err := parser.Parse(q)
 	if err != nil {
 		return "", err
@@ -0,0 +1,169 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+const planSection = "plan"
+
+type explainer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// planNode is the plan shape, without the costs and row estimates that
+// changes with the data.
+type planNode struct {
+	Message    string     `json:"message"` // e.g. No tables used.
+	Table      *planTable `json:"table"`
+	NestedLoop []planNode `json:"nested_loop"`
+	Ordering   *planNode  `json:"ordering_operation"`
+	Grouping   *planNode  `json:"grouping_operation"`
+	Distinct   *planNode  `json:"duplicates_removal"`
+	Union      *planUnion `json:"union_result"`
+
+	UsingFilesort       bool `json:"using_filesort"`
+	UsingTemporaryTable bool `json:"using_temporary_table"`
+}
+
+type planTable struct {
+	TableName  string `json:"table_name"`
+	AccessType string `json:"access_type"`
+	Key        string `json:"key"`
+	Subquery   *struct {
+		QueryBlock planNode `json:"query_block"`
+	} `json:"materialized_from_subquery"`
+}
+
+// planUnion is the plan of each SELECT of the UNION.
+type planUnion struct {
+	UsingTemporaryTable bool `json:"using_temporary_table"`
+	QuerySpecifications []struct {
+		QueryBlock planNode `json:"query_block"`
+	} `json:"query_specifications"`
+}
+
+// explain runs `EXPLAIN FORMAT=JSON` for SELECT queries, and returns the
+// plan shape.
+// Other queries are not explained, since EXPLAIN does not run them.
+func explain(ctx context.Context, db explainer, query string, args ...any) (string, error) {
+	ok, err := isSelect(query)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	var b []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+query, args...).Scan(&b); err != nil {
+		return "", fmt.Errorf("mysqldump: explain: %w", err)
+	}
+
+	var plan struct {
+		QueryBlock planNode `json:"query_block"`
+	}
+	if err := json.Unmarshal(b, &plan); err != nil {
+		return "", fmt.Errorf("mysqldump: explain: %w", err)
+	}
+
+	var sb strings.Builder
+	writePlan(&sb, plan.QueryBlock, 0)
+
+	// An empty plan is compared as no plan, so the plans that are not
+	// modelled are reported instead.
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("mysqldump: explain: unsupported plan: %s", b)
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func isSelect(query string) (bool, error) {
+	stmt, err := parser.Parse(query)
+	if err != nil {
+		return false, err
+	}
+
+	switch stmt.(type) {
+	case *sqlparser.Select, *sqlparser.Union:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// writePlan writes the plan as an indented tree, e.g.
+//
+//	Order (filesort)
+//	  Nested Loop
+//	    ALL on orders
+//	    eq_ref on users using PRIMARY
+func writePlan(sb *strings.Builder, n planNode, depth int) {
+	line := func(s string) {
+		sb.WriteString(strings.Repeat("  ", depth))
+		sb.WriteString(s)
+		sb.WriteString("\n")
+	}
+
+	// The operations wraps the rest of the plan.
+	ops := []struct {
+		name string
+		node *planNode
+	}{
+		{"Order", n.Ordering},
+		{"Group", n.Grouping},
+		{"Distinct", n.Distinct},
+	}
+	for _, op := range ops {
+		if op.node == nil {
+			continue
+		}
+
+		name := op.name
+		if op.node.UsingFilesort {
+			name += " (filesort)"
+		}
+		if op.node.UsingTemporaryTable {
+			name += " (temporary)"
+		}
+		line(name)
+		writePlan(sb, *op.node, depth+1)
+	}
+
+	if u := n.Union; u != nil {
+		name := "Union"
+		if u.UsingTemporaryTable {
+			name += " (temporary)"
+		}
+		line(name)
+		for _, q := range u.QuerySpecifications {
+			writePlan(sb, q.QueryBlock, depth+1)
+		}
+	}
+
+	if n.Message != "" {
+		line(n.Message)
+	}
+
+	if len(n.NestedLoop) > 0 {
+		line("Nested Loop")
+		for _, child := range n.NestedLoop {
+			writePlan(sb, child, depth+1)
+		}
+	}
+
+	if t := n.Table; t != nil {
+		s := fmt.Sprintf("%s on %s", t.AccessType, t.TableName)
+		if t.Key != "" {
+			s += " using " + t.Key
+		}
+		line(s)
+
+		if t.Subquery != nil {
+			writePlan(sb, t.Subquery.QueryBlock, depth+1)
+		}
+	}
+}
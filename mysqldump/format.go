@@ -0,0 +1,47 @@
+package mysqldump
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alextanhongpin/testdump/pkg/sqlformat"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// format normalizes the query with sqlparser.String, and then breaks the
+// clauses into new lines using the tokens from the vitess tokenizer.
+// The AST is only used to normalize the query, since vitess does not provide
+// a multi-line printer.
+func format(query string) (string, error) {
+	q, err := normalize(query)
+	if err != nil {
+		return "", err
+	}
+
+	var tokens []sqlformat.Token
+
+	tkn := parser.NewStringTokenizer(q)
+	for {
+		pos := tkn.Pos
+		typ, val := tkn.Scan()
+		if typ == 0 {
+			break
+		}
+		if typ == sqlparser.LEX_ERROR {
+			return "", fmt.Errorf("mysqldump: format: unexpected %q", val)
+		}
+		if typ == sqlparser.COMMENT {
+			continue
+		}
+
+		// The tokenizer only returns the end of the token.
+		start := pos + len(q[pos:tkn.Pos]) - len(strings.TrimLeft(q[pos:tkn.Pos], " \t\r\n"))
+		tokens = append(tokens, sqlformat.Token{
+			Start:   start,
+			End:     tkn.Pos,
+			Keyword: sqlparser.KeywordString(typ) != "",
+		})
+	}
+
+	return sqlformat.Indent(q, tokens), nil
+}
@@ -7,7 +7,8 @@ require (
 	github.com/alextanhongpin/testdump/pkg/diff v0.0.0-20260202060108-045aa6c3cb8b
 	github.com/alextanhongpin/testdump/pkg/file v0.0.0-20260202060108-045aa6c3cb8b
 	github.com/alextanhongpin/testdump/pkg/snapshot v0.0.0-20260202060108-045aa6c3cb8b
-	github.com/alextanhongpin/testdump/pkg/sqlformat v0.0.0-20260202060108-045aa6c3cb8b
+	github.com/alextanhongpin/testdump/pkg/sqlformat v0.0.0-20261019031221-48ff0785b84c
+	github.com/alextanhongpin/testdump/pkg/sqlrecorder v0.0.0-20261019043534-a6c70acb1f1f
 	github.com/google/go-cmp v0.7.0
 	golang.org/x/tools v0.41.0
 	vitess.io/vitess v0.23.0
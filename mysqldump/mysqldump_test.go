@@ -1,6 +1,8 @@
 package mysqldump_test
 
 import (
+	"fmt"
+	"math/rand/v2"
 	"testing"
 	"time"
 
@@ -25,6 +27,23 @@ func TestIgnoreFields(t *testing.T) {
 	mysqldump.Dump(t, dump, mysqldump.IgnoreArgs(":v2"))
 }
 
+func TestArgOptions(t *testing.T) {
+	dump := &mysqldump.SQL{
+		Query: `insert into users (id, email, password, created_at) values (?, ?, ?, ?)`,
+		Args:  []any{newUUID(), "john.appleseed@mail.com", "secret", time.Now()},
+	}
+
+	mysqldump.Dump(t, dump,
+		mysqldump.MaskArgs("[REDACTED]", ":v3"),
+		mysqldump.IgnoreArgPatterns(mysqldump.UUIDPattern, mysqldump.TimestampPattern),
+	)
+}
+
+// newUUID returns a random UUID v7, e.g. for the generated primary keys.
+func newUUID() string {
+	return fmt.Sprintf("%08x-%04x-7%03x-8000-%012x", rand.Uint32(), rand.Uint32()&0xffff, rand.Uint32()&0xfff, rand.Uint64()&0xffffffffffff)
+}
+
 func TestTransformer(t *testing.T) {
 	dump := &mysqldump.SQL{
 		Query: `select * from users where name = ? and id = ?`,
@@ -44,6 +63,28 @@ func TestCompareQuery(t *testing.T) {
 		`select name
 from users
 		where name = ?`,
+		// The inline literals are treated as bind variables.
+		`select name from users where name = 'John'`,
+		`select name from users where name = :name`,
+	}
+
+	for _, v := range vars {
+		ok, err := mysqldump.CompareQuery(base, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("expected %s to match %s", base, v)
+		}
+	}
+}
+
+func TestCompareQueryList(t *testing.T) {
+	base := `select name from users where id in (?)`
+	vars := []string{
+		`select name from users where id in (?, ?, ?)`,
+		`select name from users where id in (1, 2)`,
+		`select name from users where id in ::ids`,
 	}
 
 	for _, v := range vars {
@@ -55,4 +96,12 @@ from users
 			t.Errorf("expected %s to match %s", base, v)
 		}
 	}
+
+	ok, err := mysqldump.CompareQuery(base, `select name from users where id not in (?)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("expected in and not in to not match")
+	}
 }
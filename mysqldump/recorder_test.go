@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/alextanhongpin/testdump/mysqldump"
@@ -37,6 +39,260 @@ func TestRecorder(t *testing.T) {
 	}
 }
 
+func TestRecorderAssertions(t *testing.T) {
+	db := newMockDB(t,
+		[]string{"id", "name"},
+		"1", "Alice",
+		"1", "Alice",
+		"1", "Alice",
+	)
+
+	rec := mysqldump.NewRecorder(t,
+		mysqldump.MaxQueries(3),
+		mysqldump.DetectNPlusOne(),
+		mysqldump.Summary(),
+	).DB(db)
+	ctx := context.Background()
+
+	var id int
+	var name string
+	// Repeating the query with the same args is not flagged as N+1.
+	for range 2 {
+		if err := rec.QueryRowContext(ctx, "select * from users where id = ?", 1).Scan(&id, &name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := rec.QueryRowContext(ctx, "select * from users where name = ?", "Alice").Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorderSummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	for range 3 {
+		mock.ExpectExec("insert(.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	rec := mysqldump.NewRecorder(t,
+		mysqldump.DetectNPlusOne(),
+		mysqldump.Summary(),
+		mysqldump.File("users"),
+	).DB(db)
+	ctx := context.Background()
+
+	// Writes with different args are not N+1 queries.
+	for _, name := range []string{"Alice", "Bob"} {
+		if _, err := rec.ExecContext(ctx, "insert into users (name) values (?)", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The multi-line query is kept in the summary.
+	if _, err := rec.ExecContext(ctx, "insert into users (name, bio) values (?, 'first line\nsecond line')", "Carol"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorderExplain(t *testing.T) {
+	db := newMockDB(t,
+		[]string{"id", "name"},
+		"1", "Alice",
+	)
+
+	// The plan of a live connection, with the costs and row estimates.
+	plan := `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {
+      "query_cost": "1.35"
+    },
+    "ordering_operation": {
+      "using_filesort": true,
+      "nested_loop": [
+        {
+          "table": {
+            "table_name": "o",
+            "access_type": "ALL",
+            "rows_examined_per_scan": 1,
+            "filtered": "100.00",
+            "cost_info": {
+              "read_cost": "0.25",
+              "prefix_cost": "0.35"
+            }
+          }
+        },
+        {
+          "table": {
+            "table_name": "u",
+            "access_type": "eq_ref",
+            "possible_keys": ["PRIMARY"],
+            "key": "PRIMARY",
+            "rows_examined_per_scan": 1,
+            "filtered": "100.00"
+          }
+        }
+      ]
+    }
+  }
+}`
+	explainDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		explainDB.Close()
+	})
+	mock.ExpectQuery("EXPLAIN FORMAT=JSON (.+)").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(plan))
+
+	rec := mysqldump.NewRecorder(t, mysqldump.Explain(explainDB)).DB(db)
+	ctx := context.Background()
+
+	var id int
+	var name string
+	if err := rec.QueryRowContext(ctx, "select u.id, u.name from users u join orders o on o.user_id = u.id where u.id = ? order by o.created_at", 1).Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecorderExplainUnion(t *testing.T) {
+	db := newMockDB(t,
+		[]string{"name"},
+		"Alice",
+	)
+
+	plan := `{
+  "query_block": {
+    "union_result": {
+      "using_temporary_table": true,
+      "table_name": "<union1,2>",
+      "access_type": "ALL",
+      "query_specifications": [
+        {
+          "query_block": {
+            "select_id": 1,
+            "table": {
+              "table_name": "users",
+              "access_type": "ALL",
+              "rows_examined_per_scan": 1
+            }
+          }
+        },
+        {
+          "query_block": {
+            "select_id": 2,
+            "message": "No tables used"
+          }
+        }
+      ]
+    }
+  }
+}`
+	explainDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		explainDB.Close()
+	})
+	mock.ExpectQuery("EXPLAIN FORMAT=JSON (.+)").
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(plan))
+
+	rec := mysqldump.NewRecorder(t, mysqldump.Explain(explainDB)).DB(db)
+
+	var name string
+	if err := rec.QueryRowContext(context.Background(), "select name from users union select 'Bob'").Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecorderTranscript(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectExec("insert(.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("insert(.+)").WillReturnError(errors.New("Error 1062 (23000): Duplicate entry 'Alice' for key 'users.name'"))
+	mock.ExpectRollback()
+	mock.ExpectQuery("select(.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	rec := mysqldump.NewRecorder(t, mysqldump.AsTranscript(), mysqldump.Summary()).DB(db)
+	ctx := context.Background()
+
+	tx, err := rec.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The deferred rollback is not recorded, since the transaction is done.
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "insert into users (name) values (?)", "Alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "insert into users (name) values (?)", "Alice"); err == nil {
+		t.Fatal("want error, got nil")
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := rec.QueryRowContext(ctx, "select count(*) from users").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecorderForQuery(t *testing.T) {
+	db := newMockDB(t,
+		[]string{"id", "name"},
+		"1", "Alice",
+		"1", "Alice",
+	)
+
+	rec := mysqldump.NewRecorder(t,
+		mysqldump.ForQuery("select * from users where id = ? and updated_at > ?",
+			mysqldump.IgnoreArgs(":v2"),
+		),
+	).DB(db)
+	ctx := context.Background()
+
+	var id int
+	var name string
+	if err := rec.QueryRowContext(ctx, "select * from users where id = ? and updated_at > ?", 1, time.Now()).Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+	// The args of the other queries are still compared.
+	if err := rec.QueryRowContext(ctx, "select * from users where name = ?", "Alice").Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func newMockDB(t *testing.T, cols []string, vals ...string) *sql.DB {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -0,0 +1,11 @@
+-- query --
+insert into users(id, email, `password`, created_at) values (:v1, :v2, :v3, :v4)
+
+-- args --
+{
+ ":v1": "90b564af-0000-4000-8000-1d91e5445b7c",
+ ":v2": "john.appleseed@mail.com",
+ ":v3": "[REDACTED]",
+ ":v4": "2026-10-19T03:15:25.522212456Z"
+}
+
@@ -0,0 +1,8 @@
+-- query --
+select * from users where id = :v1
+
+-- args --
+{
+ ":v1": 1
+}
+
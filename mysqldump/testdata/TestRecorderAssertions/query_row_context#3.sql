@@ -0,0 +1,8 @@
+-- query --
+select * from users where `name` = :v1
+
+-- args --
+{
+ ":v1": "Alice"
+}
+
@@ -0,0 +1,4 @@
+-- summary --
+2 select * from users where id = :v1
+1 select * from users where `name` = :v1
+
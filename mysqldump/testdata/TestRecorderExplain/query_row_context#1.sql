@@ -0,0 +1,14 @@
+-- query --
+select u.id, u.`name` from users as u join orders as o on o.user_id = u.id where u.id = :v1 order by o.created_at asc
+
+-- args --
+{
+ ":v1": 1
+}
+
+-- plan --
+Order (filesort)
+  Nested Loop
+    ALL on o
+    eq_ref on u using PRIMARY
+
@@ -0,0 +1,8 @@
+-- query --
+select `name` from users union select 'Bob' from dual
+
+-- plan --
+Union (temporary)
+  ALL on users
+  No tables used
+
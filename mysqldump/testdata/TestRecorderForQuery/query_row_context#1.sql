@@ -0,0 +1,9 @@
+-- query --
+select * from users where id = :v1 and updated_at > :v2
+
+-- args --
+{
+ ":v1": 1,
+ ":v2": "2026-10-19T03:15:25.525953299Z"
+}
+
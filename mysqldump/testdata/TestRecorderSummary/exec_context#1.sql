@@ -0,0 +1,8 @@
+-- query --
+insert into users(`name`) values (:v1)
+
+-- args --
+{
+ ":v1": "Alice"
+}
+
@@ -0,0 +1,8 @@
+-- query --
+insert into users(`name`, bio) values (:v1, 'first line\nsecond line')
+
+-- args --
+{
+ ":v1": "Carol"
+}
+
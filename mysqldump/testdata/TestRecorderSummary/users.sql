@@ -0,0 +1,4 @@
+-- summary --
+2 insert into users(`name`) values (:v1)
+1 insert into users(`name`, bio) values (:v1, 'first line\nsecond line')
+
@@ -0,0 +1,28 @@
+-- begin --
+-- exec_context --
+insert into users(`name`) values (:v1)
+
+-- args --
+{
+ ":v1": "Alice"
+}
+
+-- exec_context --
+insert into users(`name`) values (:v1)
+
+-- args --
+{
+ ":v1": "Alice"
+}
+
+-- error --
+Error 1062 (23000): Duplicate entry 'Alice' for key 'users.name'
+
+-- rollback --
+-- query_row_context --
+select count(*) from users
+
+-- summary --
+2 insert into users(`name`) values (:v1)
+1 select count(*) from users
+
@@ -0,0 +1,185 @@
+package pgdump
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxHexBytes is the maximum length of the bytes written as hex. Longer bytes
+// are written as the length and the checksum.
+const maxHexBytes = 32
+
+// Arg is an arg with the Go type, e.g. time.Time, uuid.UUID or pgtype.Text.
+// The value is resolved with driver.Valuer, and the bytes are written as hex,
+// e.g. `\x0102`, or as the length and the checksum when it is long.
+//
+// Args of the basic types, e.g. string, bool and numbers, are written as is,
+// since JSON already keeps the type.
+type Arg struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// argsMap names each arg `$n`, where `n` indicates the index of the arg in
+// the slice, or `@name` for sql.NamedArg.
+func argsMap(args []any) (map[string]any, error) {
+	m := make(map[string]any)
+	for i, v := range args {
+		key, v := argKey(i, v)
+
+		a, err := newArg(v)
+		if err != nil {
+			return nil, fmt.Errorf("pgdump: arg %s: %w", key, err)
+		}
+		m[key] = a
+	}
+
+	return m, nil
+}
+
+// argKey returns the key and the value of the i-th arg.
+func argKey(i int, v any) (string, any) {
+	if n, ok := v.(sql.NamedArg); ok {
+		return "@" + n.Name, n.Value
+	}
+
+	return fmt.Sprintf("$%d", i+1), v
+}
+
+// maskArgs replaces the values of the args with the keys with the mask.
+// The Go type is kept, so that the type is still compared.
+func maskArgs(args []any, mask string, keys ...string) ([]any, error) {
+	res := make([]any, len(args))
+	for i, v := range args {
+		key, val := argKey(i, v)
+		if !slices.Contains(keys, key) {
+			res[i] = v
+			continue
+		}
+
+		a, err := newArg(val)
+		if err != nil {
+			return nil, fmt.Errorf("pgdump: arg %s: %w", key, err)
+		}
+
+		var masked any = mask
+		if a, ok := a.(Arg); ok {
+			masked = Arg{Type: a.Type, Value: mask}
+		}
+
+		if n, ok := v.(sql.NamedArg); ok {
+			res[i] = sql.Named(n.Name, masked)
+		} else {
+			res[i] = masked
+		}
+	}
+
+	return res, nil
+}
+
+func newArg(v any) (any, error) {
+	switch v.(type) {
+	case nil, string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v, nil
+	case Arg, *Arg:
+		// Already read from the snapshot.
+		return v, nil
+	}
+
+	typ := fmt.Sprintf("%T", v)
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return Arg{Type: typ}, nil
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		v = val
+	}
+
+	if b, ok := v.([]byte); ok {
+		return Arg{Type: typ, Value: formatBytes(b)}, nil
+	}
+
+	return Arg{Type: typ, Value: v}, nil
+}
+
+// formatBytes formats the bytes similar to the Postgres bytea hex format.
+func formatBytes(b []byte) string {
+	if len(b) <= maxHexBytes {
+		return `\x` + hex.EncodeToString(b)
+	}
+
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf("%d bytes, sha256:%x", len(b), sum[:8])
+}
+
+// readArgs reads the args map written by Write back into a slice.
+// The named args are placed after the positional args, sorted by name.
+func readArgs(data []byte) ([]any, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var args []any
+	var named []any
+	for _, k := range keys {
+		v, err := readArg(m[k])
+		if err != nil {
+			return nil, err
+		}
+
+		if name, ok := strings.CutPrefix(k, "@"); ok {
+			named = append(named, sql.Named(name, v))
+			continue
+		}
+
+		i, err := strconv.Atoi(strings.TrimPrefix(k, "$")) // Index starts at 1
+		if err != nil {
+			return nil, err
+		}
+		if i > len(args) {
+			args = append(args, make([]any, i-len(args))...)
+		}
+		args[i-1] = v
+	}
+
+	return append(args, named...), nil
+}
+
+func readArg(b json.RawMessage) (any, error) {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	// Args with the Go type.
+	if m, ok := v.(map[string]any); ok && len(m) == 2 {
+		typ, ok := m["type"].(string)
+		if _, has := m["value"]; ok && has {
+			return Arg{Type: typ, Value: m["value"]}, nil
+		}
+	}
+
+	return v, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/alextanhongpin/testdump/pkg/diff"
+	"github.com/alextanhongpin/testdump/pkg/sqlrecorder"
 	"github.com/google/go-cmp/cmp"
 	pg_query "github.com/pganalyze/pg_query_go/v6"
 )
@@ -59,13 +60,15 @@ func (c *comparer) compare(snapshot, received *SQL) error {
 		return fmt.Errorf("Args: %w", err)
 	}
 
+	if err := comparer(snapshot.Plan, received.Plan); err != nil {
+		return fmt.Errorf("Plan: %w", err)
+	}
+
 	return nil
 }
 
-type SQL struct {
-	Query string
-	Args  []any
-}
+// SQL is the query and args of a single call.
+type SQL = sqlrecorder.SQL
 
 // CompareQuery checks if two queries are equal, ignoring variables.
 func CompareQuery(a, b string) (bool, error) {
@@ -83,11 +86,11 @@ func CompareQuery(a, b string) (bool, error) {
 
 // toMap converts the slice args into a map for better diff.
 // Each key is named `$n`, where `n` indicates the index of the arg in the
-// slice.
+// slice, or `@name` for sql.NamedArg.
 func toMap(s []any) (any, error) {
-	m := make(map[string]any)
-	for k, v := range s {
-		m[fmt.Sprintf("$%d", k+1)] = v
+	m, err := argsMap(s)
+	if err != nil {
+		return nil, err
 	}
 
 	// Marshal/unmarshal to avoid type issues such as
@@ -3,9 +3,6 @@ package pgdump
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
-	"strconv"
-	"strings"
 
 	pg_query "github.com/pganalyze/pg_query_go/v6"
 	"golang.org/x/tools/txtar"
@@ -39,24 +36,13 @@ func Read(b []byte) (*SQL, error) {
 		case querySection:
 			d.Query = string(data)
 		case argsSection:
-			var a any
-			if err := json.Unmarshal(data, &a); err != nil {
+			args, err := readArgs(data)
+			if err != nil {
 				return nil, err
 			}
-
-			m, ok := a.(map[string]any)
-			if ok {
-				// Sort to ensure the order is consistent.
-				d.Args = make([]any, len(m))
-				for k, v := range m {
-					key := strings.ReplaceAll(k, "$", "")
-					i, err := strconv.Atoi(key) // Index starts at 1
-					if err != nil {
-						return nil, err
-					}
-					d.Args[i-1] = v
-				}
-			}
+			d.Args = args
+		case planSection:
+			d.Plan = string(data)
 		}
 	}
 
@@ -78,13 +64,12 @@ func Write(sql *SQL, transformers ...func(*SQL) error) ([]byte, error) {
 
 	var a []byte
 	if len(sql.Args) > 0 {
-		args := make(map[string]any)
-		for i, v := range sql.Args {
-			k := fmt.Sprintf("$%d", i+1)
-			args[k] = v
+		m, err := argsMap(sql.Args)
+		if err != nil {
+			return nil, err
 		}
 
-		a, err = json.MarshalIndent(args, "", " ")
+		a, err = json.MarshalIndent(m, "", " ")
 		if err != nil {
 			return nil, err
 		}
@@ -105,6 +90,14 @@ func Write(sql *SQL, transformers ...func(*SQL) error) ([]byte, error) {
 		})
 	}
 
+	// Plan.
+	if sql.Plan != "" {
+		arc.Files = append(arc.Files, txtar.File{
+			Name: planSection,
+			Data: appendNewLine([]byte(sql.Plan)),
+		})
+	}
+
 	return txtar.Format(arc), nil
 }
 
@@ -0,0 +1,95 @@
+package pgdump
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v6"
+)
+
+const planSection = "plan"
+
+type explainer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// planNode is the plan shape, without the costs and row estimates that
+// changes with the data.
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	JoinType     string     `json:"Join Type"`
+	Strategy     string     `json:"Strategy"`
+	RelationName string     `json:"Relation Name"`
+	IndexName    string     `json:"Index Name"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// explain runs `EXPLAIN (FORMAT JSON)` for SELECT queries, and returns the
+// plan shape.
+// Other queries are not explained, since EXPLAIN does not run them.
+func explain(ctx context.Context, db explainer, query string, args ...any) (string, error) {
+	ok, err := isSelect(query)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	var b []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&b); err != nil {
+		return "", fmt.Errorf("pgdump: explain: %w", err)
+	}
+
+	var plans []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(b, &plans); err != nil {
+		return "", fmt.Errorf("pgdump: explain: %w", err)
+	}
+
+	var sb strings.Builder
+	for _, p := range plans {
+		writePlan(&sb, p.Plan, 0)
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func isSelect(query string) (bool, error) {
+	res, err := pg_query.Parse(query)
+	if err != nil {
+		return false, err
+	}
+
+	stmts := res.GetStmts()
+	return len(stmts) == 1 && stmts[0].GetStmt().GetSelectStmt() != nil, nil
+}
+
+// writePlan writes the plan as an indented tree, e.g.
+//
+//	Hash Join (Inner)
+//	  Seq Scan on orders
+//	  Hash
+//	    Index Scan on users using users_pkey
+func writePlan(sb *strings.Builder, n planNode, depth int) {
+	sb.WriteString(strings.Repeat("  ", depth))
+	sb.WriteString(n.NodeType)
+	if n.JoinType != "" {
+		fmt.Fprintf(sb, " (%s)", n.JoinType)
+	}
+	if n.Strategy != "" {
+		fmt.Fprintf(sb, " (%s)", n.Strategy)
+	}
+	if n.RelationName != "" {
+		fmt.Fprintf(sb, " on %s", n.RelationName)
+	}
+	if n.IndexName != "" {
+		fmt.Fprintf(sb, " using %s", n.IndexName)
+	}
+	sb.WriteString("\n")
+
+	for _, child := range n.Plans {
+		writePlan(sb, child, depth+1)
+	}
+}
@@ -0,0 +1,36 @@
+package pgdump
+
+import (
+	"strings"
+
+	"github.com/alextanhongpin/testdump/pkg/sqlformat"
+	pg_query "github.com/pganalyze/pg_query_go/v6"
+)
+
+// format deparses the query and indents the clauses, using the tokens from
+// the Postgres scanner.
+func format(query string) (string, error) {
+	q, err := normalize(query)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := pg_query.Scan(q)
+	if err != nil {
+		return "", err
+	}
+
+	tokens := make([]sqlformat.Token, len(res.GetTokens()))
+	for i, t := range res.GetTokens() {
+		text := q[t.GetStart():t.GetEnd()]
+		tokens[i] = sqlformat.Token{
+			Start: int(t.GetStart()),
+			End:   int(t.GetEnd()),
+			// Deparse upper-cases the keywords, but not the identifiers that
+			// are unreserved keywords, e.g. name.
+			Keyword: t.GetKeywordKind() != pg_query.KeywordKind_NO_KEYWORD && text == strings.ToUpper(text),
+		}
+	}
+
+	return sqlformat.Indent(q, tokens), nil
+}
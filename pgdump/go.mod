@@ -9,7 +9,8 @@ require (
 	github.com/alextanhongpin/testdump/pkg/diff v0.0.0-20260202055853-a19b226ed7bf
 	github.com/alextanhongpin/testdump/pkg/file v0.0.0-20260202055853-a19b226ed7bf
 	github.com/alextanhongpin/testdump/pkg/snapshot v0.0.0-20260202055853-a19b226ed7bf
-	github.com/alextanhongpin/testdump/pkg/sqlformat v0.0.0-20260202055853-a19b226ed7bf
+	github.com/alextanhongpin/testdump/pkg/sqlformat v0.0.0-20261019031221-48ff0785b84c
+	github.com/alextanhongpin/testdump/pkg/sqlrecorder v0.0.0-20261019043534-a6c70acb1f1f
 	github.com/google/go-cmp v0.7.0
 	github.com/pganalyze/pg_query_go/v4 v4.2.3
 	github.com/pganalyze/pg_query_go/v6 v6.2.2
@@ -1,23 +0,0 @@
-package internal
-
-import (
-	"slices"
-
-	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
-)
-
-func IgnoreMapEntries(keys ...string) cmp.Option {
-	slices.Sort(keys)
-	keys = slices.Compact(keys)
-
-	return cmpopts.IgnoreMapEntries(func(k string, v any) bool {
-		for _, key := range keys {
-			if key == k {
-				return true
-			}
-		}
-
-		return false
-	})
-}
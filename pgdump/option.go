@@ -1,90 +1,65 @@
 package pgdump
 
 import (
-	"os"
-	"strconv"
+	"context"
 
-	"github.com/alextanhongpin/testdump/pgdump/internal"
-	"github.com/alextanhongpin/testdump/pkg/sqlformat"
-	"github.com/google/go-cmp/cmp"
+	"github.com/alextanhongpin/testdump/pkg/sqlrecorder"
 )
 
-const env = "TESTDUMP"
-
-type options struct {
-	cmpOpts      []cmp.Option
-	colors       bool
-	env          string
-	file         string
-	transformers []func(*SQL) error
-}
-
-func newOptions() *options {
-	return &options{
-		colors: true,
-		env:    env,
-	}
-}
-
-func (o *options) apply(opts ...Option) *options {
-	for _, opt := range opts {
-		opt(o)
-	}
-	return o
-}
-
-func (o *options) overwrite() bool {
-	t, _ := strconv.ParseBool(os.Getenv(o.env))
-	return t
-}
-
-func (o *options) encoder() *encoder {
-	return &encoder{
-		marshalFns: o.transformers,
-	}
-}
-
-func (o *options) comparer() *comparer {
-	return &comparer{
-		opts:   o.cmpOpts,
-		colors: o.colors,
-	}
-}
-
-type Option func(o *options)
-
-func File(file string) Option {
-	return func(o *options) {
-		o.file = file
-	}
-}
-
-func Env(env string) Option {
-	return func(o *options) {
-		o.env = env
-	}
-}
+// Patterns for IgnoreArgPatterns.
+const (
+	UUIDPattern      = sqlrecorder.UUIDPattern
+	TimestampPattern = sqlrecorder.TimestampPattern
+)
 
-func Colors(colors bool) Option {
-	return func(o *options) {
-		o.colors = colors
-	}
-}
+type Option = sqlrecorder.Option
+
+// The options shared by the SQL dumpers, see the sqlrecorder package for the
+// docs.
+var (
+	File              = sqlrecorder.File
+	Env               = sqlrecorder.Env
+	Colors            = sqlrecorder.Colors
+	IgnoreArgs        = sqlrecorder.IgnoreArgs
+	IgnoreArgPatterns = sqlrecorder.IgnoreArgPatterns
+	ForQuery          = sqlrecorder.ForQuery
+	AsTranscript      = sqlrecorder.AsTranscript
+	MaxQueries        = sqlrecorder.MaxQueries
+	DetectNPlusOne    = sqlrecorder.DetectNPlusOne
+	Summary           = sqlrecorder.Summary
+	Transformers      = sqlrecorder.Transformers
+)
 
-func IgnoreArgs(args ...string) Option {
-	return func(o *options) {
-		o.cmpOpts = append(o.cmpOpts, internal.IgnoreMapEntries(args...))
-	}
+// MaskArgs replaces the values of the args with the mask in the snapshot,
+// e.g. `$1` or `@name` for sql.NamedArg.
+// Unlike IgnoreArgs, the args must still be present, and the Go type is still
+// compared.
+func MaskArgs(mask string, args ...string) Option {
+	return Transformers(func(s *SQL) error {
+		res, err := maskArgs(s.Args, mask, args...)
+		if err != nil {
+			return err
+		}
+		s.Args = res
+		return nil
+	})
 }
 
-func Transformers(ts ...func(*SQL) error) Option {
-	return func(o *options) {
-		o.transformers = append(o.transformers, ts...)
-	}
+// Explain runs `EXPLAIN (FORMAT JSON)` on the db for each SELECT recorded by
+// the Recorder, and writes the plan shape to the plan section, so that plan
+// regressions, e.g. a missing index, shows up in the diff.
+// Only the node types, join types, relations and index names are kept.
+// The db must see the same data as the recorded queries, e.g. the tables
+// created in an uncommitted transaction are not visible.
+func Explain(db explainer) Option {
+	return sqlrecorder.Explain(func(ctx context.Context, query string, args ...any) (string, error) {
+		return explain(ctx, db, query, args...)
+	})
 }
 
+// Prettify formats the query with each clause on a new line.
 var Prettify = Transformers(func(s *SQL) error {
-	q, err := sqlformat.Format(s.Query)
+	q, err := format(s.Query)
 	if err != nil {
 		return err
 	}
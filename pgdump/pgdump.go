@@ -39,14 +39,22 @@ func (d *Dumper) Dump(t *testing.T, s *SQL, opts ...Option) {
 }
 
 func dump(t *testing.T, s *SQL, opts ...Option) error {
-	opt := newOptions().apply(opts...)
+	opt := dialect.QueryOptions(s.Query, opts...)
 
-	path := filepath.Join("testdata", fmt.Sprintf("%s.sql", filepath.Join(t.Name(), opt.file)))
-	f, err := file.New(path, opt.overwrite())
+	path := filepath.Join("testdata", fmt.Sprintf("%s.sql", filepath.Join(t.Name(), opt.File)))
+	f, err := file.New(path, opt.Overwrite())
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	return snapshot.Snapshot(f, opt.encoder(), opt.comparer(), s)
+	enc := &encoder{
+		marshalFns: opt.Transformers,
+	}
+	c := &comparer{
+		opts:   opt.CmpOpts,
+		colors: opt.Colors,
+	}
+
+	return snapshot.Snapshot(f, enc, c, s)
 }
@@ -1,6 +1,13 @@
 package pgdump_test
 
 import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,12 +26,78 @@ func TestDump(t *testing.T) {
 func TestIgnoreFields(t *testing.T) {
 	dump := &pgdump.SQL{
 		Query: `select * from users where name = $1 and created_at > $2`,
-		Args:  []any{"John", time.Now()},
+		Args:  []any{"John", time.Date(2024, 5, 22, 22, 6, 30, 0, time.UTC)},
 	}
 
 	pgdump.Dump(t, dump, pgdump.IgnoreArgs("$2"))
 }
 
+type status string
+
+func (s status) Value() (driver.Value, error) {
+	return strings.ToUpper(string(s)), nil
+}
+
+func TestTypedArgs(t *testing.T) {
+	var deletedAt *time.Time
+	dump := &pgdump.SQL{
+		Query: `update users set avatar = $1, thumbnail = $2, created_at = $3, deleted_at = $4, status = $6 where id = $5`,
+		Args: []any{
+			bytes.Repeat([]byte("x"), 1024),
+			[]byte("thumb"),
+			time.Date(2024, 5, 22, 22, 6, 30, 0, time.UTC),
+			deletedAt,
+			int64(1),
+			status("active"),
+		},
+	}
+
+	pgdump.Dump(t, dump)
+}
+
+func TestNamedArgs(t *testing.T) {
+	// Postgres does not have named placeholders, but the drivers that
+	// rewrite them still pass sql.NamedArg.
+	dump := &pgdump.SQL{
+		Query: `select * from users where id = $1`,
+		Args:  []any{int64(1), sql.Named("status", "active")},
+	}
+
+	b, err := pgdump.Write(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte(`"@status": "active"`)) {
+		t.Errorf("want the named arg keyed by @status, got:\n%s", b)
+	}
+
+	got, err := pgdump.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []any{float64(1), sql.Named("status", "active")}
+	if !reflect.DeepEqual(want, got.Args) {
+		t.Errorf("Read() args: want %#v, got %#v", want, got.Args)
+	}
+}
+
+func TestArgOptions(t *testing.T) {
+	dump := &pgdump.SQL{
+		Query: `insert into users (id, email, password, created_at) values ($1, $2, $3, $4)`,
+		Args:  []any{newUUID(), "john.appleseed@mail.com", "secret", time.Now()},
+	}
+
+	pgdump.Dump(t, dump,
+		pgdump.MaskArgs("[REDACTED]", "$3"),
+		pgdump.IgnoreArgPatterns(pgdump.UUIDPattern, pgdump.TimestampPattern),
+	)
+}
+
+// newUUID returns a random UUID v7, e.g. for the generated primary keys.
+func newUUID() string {
+	return fmt.Sprintf("%08x-%04x-7%03x-8000-%012x", rand.Uint32(), rand.Uint32()&0xffff, rand.Uint32()&0xfff, rand.Uint64()&0xffffffffffff)
+}
+
 func TestTransformer(t *testing.T) {
 	dump := &pgdump.SQL{
 		Query: `select * from users where name = $1 and id = $2`,
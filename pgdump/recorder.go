@@ -1,137 +1,71 @@
 package pgdump
 
 import (
-	"context"
-	"database/sql"
-	"fmt"
 	"testing"
-)
-
-type dbtx interface {
-	Exec(query string, args ...any) (sql.Result, error)
-	Prepare(query string) (*sql.Stmt, error)
-	Query(query string, args ...any) (*sql.Rows, error)
-	QueryRow(query string, args ...any) *sql.Row
-
-	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
-	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
-	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
-	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
-}
-
-// Recorder logs the query and args.
-type Recorder struct {
-	dumps    []*SQL
-	id       int
-	opts     []Option
-	optsByID map[int][]Option
-	seen     map[string]int
-	t        *testing.T
-}
-
-// NewRecorder ...
-func NewRecorder(t *testing.T, opts ...Option) *Recorder {
-	d := &Recorder{
-		t:        t,
-		opts:     opts,
-		optsByID: make(map[int][]Option),
-		seen:     make(map[string]int),
-	}
-	t.Cleanup(d.dump)
-	return d
-}
-
-// SetOptionsAt sets the options for the id-th call.
-func (r *Recorder) SetOptionsAt(id int, opts ...Option) {
-	r.optsByID[id] = opts
-}
-
-func (r *Recorder) Record(method, query string, args ...any) {
-	fileName := method
-	r.seen[fileName]++
-	fileName = fmt.Sprintf("%s#%d", fileName, r.seen[fileName])
-
-	r.optsByID[r.id] = append(r.optsByID[r.id], File(fileName))
-	r.dumps = append(r.dumps, &SQL{
-		Args:  args,
-		Query: query,
-	})
-	r.id++
-}
-
-func (r *Recorder) DB(db dbtx) dbtx {
-	return NewDBRecorder(db, r)
-}
 
-func (r *Recorder) dump() {
-	for i, dump := range r.dumps {
-		Dump(r.t, dump, append(r.opts, r.optsByID[i]...)...)
-	}
-}
-
-type recorder interface {
-	Record(method, query string, args ...any)
-}
-
-var _ dbtx = (*DB)(nil)
-
-type DB struct {
-	rec recorder
-	db  dbtx
-}
+	"github.com/alextanhongpin/testdump/pkg/sqlrecorder"
+	pg_query "github.com/pganalyze/pg_query_go/v6"
+)
 
-func NewDBRecorder(db dbtx, rec recorder) *DB {
-	return &DB{db: db, rec: rec}
+// dialect is the Postgres specific part of the recorder.
+var dialect = &sqlrecorder.Dialect{
+	Name:         "pgdump",
+	Fingerprint:  pg_query.Fingerprint,
+	CompareQuery: CompareQuery,
+	Normalize:    normalize,
+	IsSelect:     isSelect,
+	ArgsMap:      argsMap,
+	ReadArgs:     readArgs,
 }
 
-func (d *DB) SetDB(db dbtx) {
-	d.db = db
+func init() {
+	// Set in init, since Dump refers to the dialect for the ForQuery options.
+	dialect.Dump = Dump
 }
 
-func (d *DB) Exec(query string, args ...any) (sql.Result, error) {
-	d.rec.Record("exec", query, args...)
-
-	return d.db.Exec(query, args...)
-}
+// Recorder logs the query and args.
+type Recorder = sqlrecorder.Recorder
 
-func (d *DB) Prepare(query string) (*sql.Stmt, error) {
-	d.rec.Record("prepare", query)
+// DB records the calls made to the db.
+type DB = sqlrecorder.DB
 
-	return d.db.Prepare(query)
-}
+// Tx records the queries made in the transaction.
+type Tx = sqlrecorder.Tx
 
-func (d *DB) Query(query string, args ...any) (*sql.Rows, error) {
-	d.rec.Record("query", query, args...)
+// Transcript is the ordered list of calls made in a test.
+type Transcript = sqlrecorder.Transcript
 
-	return d.db.Query(query, args...)
-}
+// Entry is a single call in the transcript.
+type Entry = sqlrecorder.Entry
 
-func (d *DB) QueryRow(query string, args ...any) *sql.Row {
-	d.rec.Record("query_row", query, args...)
+// QueryCount is the number of calls of the queries with the same
+// fingerprint.
+type QueryCount = sqlrecorder.QueryCount
 
-	return d.db.QueryRow(query, args...)
+// NewRecorder returns a recorder that dumps the calls when the test
+// finishes.
+func NewRecorder(t *testing.T, opts ...Option) *Recorder {
+	return dialect.NewRecorder(t, opts...)
 }
 
-func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
-	d.rec.Record("exec_context", query, args...)
-
-	return d.db.ExecContext(ctx, query, args...)
+func NewDBRecorder(db sqlrecorder.DBTX, rec sqlrecorder.QueryRecorder) *DB {
+	return sqlrecorder.NewDBRecorder(db, rec)
 }
 
-func (d *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
-	d.rec.Record("prepare_context", query)
+// DumpTranscript writes the transcript into a single file.
+func DumpTranscript(t *testing.T, tr *Transcript, opts ...Option) {
+	t.Helper()
 
-	return d.db.PrepareContext(ctx, query)
+	dialect.DumpTranscript(t, tr, opts...)
 }
 
-func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-	d.rec.Record("query_context", query, args...)
-
-	return d.db.QueryContext(ctx, query, args...)
+// WriteTranscript writes each entry as a section named after the method,
+// followed by the optional args and error sections.
+func WriteTranscript(tr *Transcript, transformers ...func(*SQL) error) ([]byte, error) {
+	return dialect.WriteTranscript(tr, transformers...)
 }
 
-func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
-	d.rec.Record("query_row_context", query, args...)
-
-	return d.db.QueryRowContext(ctx, query, args...)
+// ReadTranscript reads the entries written by WriteTranscript.
+func ReadTranscript(b []byte) (*Transcript, error) {
+	return dialect.ReadTranscript(b)
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/alextanhongpin/testdump/pgdump"
@@ -37,6 +39,186 @@ func TestRecorder(t *testing.T) {
 	}
 }
 
+func TestRecorderAssertions(t *testing.T) {
+	db := newMockDB(t,
+		[]string{"id", "name"},
+		"1", "Alice",
+		"1", "Alice",
+		"1", "Alice",
+	)
+
+	rec := pgdump.NewRecorder(t,
+		pgdump.MaxQueries(3),
+		pgdump.DetectNPlusOne(),
+		pgdump.Summary(),
+	).DB(db)
+	ctx := context.Background()
+
+	var id int
+	var name string
+	// Repeating the query with the same args is not flagged as N+1.
+	for range 2 {
+		if err := rec.QueryRowContext(ctx, "select * from users where id = $1", 1).Scan(&id, &name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := rec.QueryRowContext(ctx, "select * from users where name = $1", "Alice").Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorderSummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	for range 3 {
+		mock.ExpectExec("insert(.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	rec := pgdump.NewRecorder(t,
+		pgdump.DetectNPlusOne(),
+		pgdump.Summary(),
+		pgdump.File("users"),
+	).DB(db)
+	ctx := context.Background()
+
+	// Writes with different args are not N+1 queries.
+	for _, name := range []string{"Alice", "Bob"} {
+		if _, err := rec.ExecContext(ctx, "insert into users (name) values ($1)", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The multi-line query is kept in the summary.
+	if _, err := rec.ExecContext(ctx, "insert into users (name, bio) values ($1, 'first line\nsecond line')", "Carol"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorderExplain(t *testing.T) {
+	db := newMockDB(t,
+		[]string{"id", "name"},
+		"1", "Alice",
+	)
+
+	// The plan of a live connection, with the costs and row estimates.
+	plan := `[
+  {
+    "Plan": {
+      "Node Type": "Nested Loop",
+      "Join Type": "Inner",
+      "Startup Cost": 0.29,
+      "Total Cost": 16.34,
+      "Plan Rows": 1,
+      "Plans": [
+        {
+          "Node Type": "Index Scan",
+          "Parent Relationship": "Outer",
+          "Index Name": "users_pkey",
+          "Relation Name": "users",
+          "Alias": "u",
+          "Total Cost": 8.17,
+          "Plan Rows": 1
+        },
+        {
+          "Node Type": "Seq Scan",
+          "Parent Relationship": "Inner",
+          "Relation Name": "orders",
+          "Alias": "o",
+          "Total Cost": 8.16,
+          "Plan Rows": 1
+        }
+      ]
+    }
+  }
+]`
+	explainDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		explainDB.Close()
+	})
+	mock.ExpectQuery("EXPLAIN (.+)").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow(plan))
+
+	explainer := &ctxExplainer{DB: explainDB}
+	rec := pgdump.NewRecorder(t, pgdump.Explain(explainer)).DB(db)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "explain")
+
+	var id int
+	var name string
+	if err := rec.QueryRowContext(ctx, "select u.id, u.name from users u join orders o on o.user_id = u.id where u.id = $1", 1).Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// The query is explained with the context of the call.
+	if got := explainer.ctx.Value(ctxKey{}); got != "explain" {
+		t.Errorf("explain context: want %q, got %v", "explain", got)
+	}
+}
+
+type ctxKey struct{}
+
+// ctxExplainer records the context of the EXPLAIN query.
+type ctxExplainer struct {
+	*sql.DB
+	ctx context.Context
+}
+
+func (e *ctxExplainer) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	e.ctx = ctx
+	return e.DB.QueryRowContext(ctx, query, args...)
+}
+
+func TestRecorderForQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	mock.ExpectExec("insert(.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("update(.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	rec := pgdump.NewRecorder(t,
+		pgdump.AsTranscript(),
+		pgdump.ForQuery("insert into users (id, password) values ($1, $2)",
+			pgdump.IgnoreArgs("$1"),
+			pgdump.MaskArgs("[REDACTED]", "$2"),
+		),
+		// The id of the update is still compared.
+		pgdump.ForQuery("update users set password = $1 where id = $2",
+			pgdump.MaskArgs("[REDACTED]", "$1"),
+		),
+	).DB(db)
+	ctx := context.Background()
+
+	if _, err := rec.ExecContext(ctx, "insert into users (id, password) values ($1, $2)", time.Now().UnixNano(), "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.ExecContext(ctx, "update users set password = $1 where id = $2", "new secret", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 func newMockDB(t *testing.T, cols []string, vals ...string) *sql.DB {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -64,3 +246,48 @@ func newMockDB(t *testing.T, cols []string, vals ...string) *sql.DB {
 
 	return db
 }
+
+func TestRecorderTranscript(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectExec("insert(.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("insert(.+)").WillReturnError(errors.New(`duplicate key value violates unique constraint "users_name_key"`))
+	mock.ExpectRollback()
+	mock.ExpectQuery("select(.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	rec := pgdump.NewRecorder(t, pgdump.AsTranscript(), pgdump.Summary()).DB(db)
+	ctx := context.Background()
+
+	tx, err := rec.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The deferred rollback is not recorded, since the transaction is done.
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "insert into users (name) values ($1)", "Alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "insert into users (name) values ($1)", "Alice"); err == nil {
+		t.Fatal("want error, got nil")
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := rec.QueryRowContext(ctx, "select count(*) from users").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
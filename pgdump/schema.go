@@ -0,0 +1,363 @@
+package pgdump
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alextanhongpin/testdump/pkg/diff"
+	"github.com/alextanhongpin/testdump/pkg/file"
+	"github.com/alextanhongpin/testdump/pkg/snapshot"
+	"github.com/alextanhongpin/testdump/pkg/sqlrecorder"
+	"golang.org/x/tools/txtar"
+)
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Schema is the database schema, without the system schemas.
+// The names are qualified with the schema name, e.g. public.users.
+type Schema struct {
+	Tables    []Table
+	Enums     []Enum
+	Functions []Function
+}
+
+type Table struct {
+	Name        string
+	Columns     []Column // In the order of the definition.
+	Constraints []Definition
+	Indexes     []Definition
+}
+
+type Column struct {
+	Name    string
+	Type    string
+	NotNull bool
+	Default string
+}
+
+// Definition is the name and the definition of a constraint or an index.
+type Definition struct {
+	Name       string
+	Definition string
+}
+
+type Enum struct {
+	Name   string
+	Values []string // In the sort order.
+}
+
+type Function struct {
+	Name       string // With the argument types, e.g. public.add(integer, integer).
+	Definition string
+}
+
+const excludeSchemas = `('pg_catalog', 'information_schema')`
+
+const (
+	columnsQuery = `SELECT n.nspname, c.relname, a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull, coalesce(pg_get_expr(d.adbin, d.adrelid), '')
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE c.relkind IN ('r', 'p')
+AND a.attnum > 0
+AND NOT a.attisdropped
+AND n.nspname NOT IN ` + excludeSchemas + `
+ORDER BY a.attnum`
+
+	constraintsQuery = `SELECT n.nspname, c.relname, con.conname, pg_get_constraintdef(con.oid)
+FROM pg_constraint con
+JOIN pg_class c ON c.oid = con.conrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname NOT IN ` + excludeSchemas
+
+	indexesQuery = `SELECT schemaname, tablename, indexname, indexdef
+FROM pg_indexes
+WHERE schemaname NOT IN ` + excludeSchemas
+
+	enumsQuery = `SELECT n.nspname, t.typname, e.enumlabel
+FROM pg_type t
+JOIN pg_enum e ON e.enumtypid = t.oid
+JOIN pg_namespace n ON n.oid = t.typnamespace
+WHERE n.nspname NOT IN ` + excludeSchemas + `
+ORDER BY e.enumsortorder`
+
+	// The functions created by extensions are skipped.
+	functionsQuery = `SELECT n.nspname, p.proname, pg_get_function_identity_arguments(p.oid), pg_get_functiondef(p.oid)
+FROM pg_proc p
+JOIN pg_namespace n ON n.oid = p.pronamespace
+WHERE p.prokind IN ('f', 'p')
+AND n.nspname NOT IN ` + excludeSchemas + `
+AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = p.oid AND d.deptype = 'e')`
+)
+
+// DumpSchema writes the schema of the database, e.g. after the migrations,
+// so that the DDL changes shows up in the diff.
+// The tables, enums and functions are sorted by name, so that the snapshot
+// does not depend on the order of the migrations.
+func DumpSchema(t *testing.T, db querier, opts ...Option) {
+	t.Helper()
+
+	if err := dumpSchema(t, db, opts...); err != nil {
+		t.Error(err)
+	}
+}
+
+func dumpSchema(t *testing.T, db querier, opts ...Option) error {
+	opt := sqlrecorder.NewOptions(opts...)
+
+	s, err := LoadSchema(context.Background(), db)
+	if err != nil {
+		return err
+	}
+
+	name := opt.File
+	if name == "" {
+		name = "schema"
+	}
+
+	path := filepath.Join("testdata", t.Name(), fmt.Sprintf("%s.sql", name))
+	f, err := file.New(path, opt.Overwrite())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return snapshot.Snapshot(f, new(schemaEncoder), &schemaComparer{colors: opt.Colors}, s)
+}
+
+// LoadSchema introspects the schema from the pg_catalog.
+func LoadSchema(ctx context.Context, db querier) (*Schema, error) {
+	tables := make(map[string]*Table)
+	table := func(schema, name string) *Table {
+		key := schema + "." + name
+		if _, ok := tables[key]; !ok {
+			tables[key] = &Table{Name: key}
+		}
+
+		return tables[key]
+	}
+
+	err := queryRows(ctx, db, columnsQuery, func(scan func(...any) error) error {
+		var schema, name string
+		var c Column
+		if err := scan(&schema, &name, &c.Name, &c.Type, &c.NotNull, &c.Default); err != nil {
+			return err
+		}
+
+		t := table(schema, name)
+		t.Columns = append(t.Columns, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, db, constraintsQuery, func(scan func(...any) error) error {
+		var schema, name string
+		var d Definition
+		if err := scan(&schema, &name, &d.Name, &d.Definition); err != nil {
+			return err
+		}
+
+		t := table(schema, name)
+		t.Constraints = append(t.Constraints, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, db, indexesQuery, func(scan func(...any) error) error {
+		var schema, name string
+		var d Definition
+		if err := scan(&schema, &name, &d.Name, &d.Definition); err != nil {
+			return err
+		}
+
+		t := table(schema, name)
+		t.Indexes = append(t.Indexes, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	enums := make(map[string]*Enum)
+	err = queryRows(ctx, db, enumsQuery, func(scan func(...any) error) error {
+		var schema, name, value string
+		if err := scan(&schema, &name, &value); err != nil {
+			return err
+		}
+
+		key := schema + "." + name
+		if _, ok := enums[key]; !ok {
+			enums[key] = &Enum{Name: key}
+		}
+		enums[key].Values = append(enums[key].Values, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(Schema)
+	err = queryRows(ctx, db, functionsQuery, func(scan func(...any) error) error {
+		var schema, name, args string
+		var fn Function
+		if err := scan(&schema, &name, &args, &fn.Definition); err != nil {
+			return err
+		}
+
+		fn.Name = fmt.Sprintf("%s.%s(%s)", schema, name, args)
+		fn.Definition = strings.TrimSpace(fn.Definition)
+		s.Functions = append(s.Functions, fn)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort in Go, since the order by the database depends on the collation.
+	byName := func(a, b Definition) int {
+		return strings.Compare(a.Name, b.Name)
+	}
+	for _, t := range tables {
+		slices.SortFunc(t.Constraints, byName)
+		slices.SortFunc(t.Indexes, byName)
+		s.Tables = append(s.Tables, *t)
+	}
+	for _, e := range enums {
+		s.Enums = append(s.Enums, *e)
+	}
+
+	slices.SortFunc(s.Tables, func(a, b Table) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.SortFunc(s.Enums, func(a, b Enum) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.SortFunc(s.Functions, func(a, b Function) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return s, nil
+}
+
+func queryRows(ctx context.Context, db querier, q string, fn func(scan func(...any) error) error) error {
+	rows, err := db.QueryContext(ctx, q)
+	if err != nil {
+		return fmt.Errorf("pgdump: schema: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows.Scan); err != nil {
+			return fmt.Errorf("pgdump: schema: %w", err)
+		}
+	}
+
+	return rows.Err()
+}
+
+type schemaEncoder struct{}
+
+func (e *schemaEncoder) Marshal(v any) ([]byte, error) {
+	return WriteSchema(v.(*Schema)), nil
+}
+
+// Unmarshal reads the sections, since the schema is compared as text.
+func (e *schemaEncoder) Unmarshal(b []byte) (any, error) {
+	m := make(map[string]string)
+	for _, f := range txtar.Parse(b).Files {
+		m[f.Name] = string(bytes.TrimSpace(f.Data))
+	}
+
+	return m, nil
+}
+
+// WriteSchema writes each table, enum and function as a section, e.g.
+//
+//	-- table public.users --
+//	id bigint NOT NULL DEFAULT nextval('users_id_seq'::regclass)
+//	name text NOT NULL
+//	mood public.mood
+//
+//	CONSTRAINT users_pkey PRIMARY KEY (id)
+//	CREATE UNIQUE INDEX users_pkey ON public.users USING btree (id)
+//
+//	-- enum public.mood --
+//	sad
+//	happy
+func WriteSchema(s *Schema) []byte {
+	arc := new(txtar.Archive)
+	for _, t := range s.Tables {
+		var lines []string
+		for _, c := range t.Columns {
+			line := c.Name + " " + c.Type
+			if c.NotNull {
+				line += " NOT NULL"
+			}
+			if c.Default != "" {
+				line += " DEFAULT " + c.Default
+			}
+			lines = append(lines, line)
+		}
+
+		if len(t.Constraints)+len(t.Indexes) > 0 {
+			lines = append(lines, "")
+		}
+		for _, c := range t.Constraints {
+			lines = append(lines, fmt.Sprintf("CONSTRAINT %s %s", c.Name, c.Definition))
+		}
+		for _, i := range t.Indexes {
+			lines = append(lines, i.Definition)
+		}
+
+		arc.Files = append(arc.Files, txtar.File{
+			Name: "table " + t.Name,
+			Data: appendNewLine([]byte(strings.Join(lines, "\n"))),
+		})
+	}
+
+	for _, e := range s.Enums {
+		arc.Files = append(arc.Files, txtar.File{
+			Name: "enum " + e.Name,
+			Data: appendNewLine([]byte(strings.Join(e.Values, "\n"))),
+		})
+	}
+
+	for _, fn := range s.Functions {
+		arc.Files = append(arc.Files, txtar.File{
+			Name: "function " + fn.Name,
+			Data: appendNewLine([]byte(fn.Definition)),
+		})
+	}
+
+	return txtar.Format(arc)
+}
+
+type schemaComparer struct {
+	colors bool
+}
+
+func (c *schemaComparer) Compare(a, b any) error {
+	comparer := diff.Text
+	if c.colors {
+		comparer = diff.ANSI
+	}
+
+	if err := comparer(a, b); err != nil {
+		return fmt.Errorf("Schema: %w", err)
+	}
+
+	return nil
+}
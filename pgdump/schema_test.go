@@ -0,0 +1,55 @@
+package pgdump_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alextanhongpin/testdump/pgdump"
+)
+
+func TestDumpSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	// The rows are not sorted, to show that the schema is sorted by name.
+	mock.ExpectQuery("FROM pg_attribute").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "attname", "format_type", "attnotnull", "default"}).
+			AddRow("public", "users", "id", "bigint", true, "nextval('users_id_seq'::regclass)").
+			AddRow("public", "orders", "id", "bigint", true, "nextval('orders_id_seq'::regclass)").
+			AddRow("public", "users", "name", "character varying(255)", true, "").
+			AddRow("public", "orders", "user_id", "bigint", true, "").
+			AddRow("public", "users", "mood", "public.mood", false, "'ok'::public.mood"))
+	mock.ExpectQuery("FROM pg_constraint").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "conname", "def"}).
+			AddRow("public", "orders", "orders_user_id_fkey", "FOREIGN KEY (user_id) REFERENCES public.users(id)").
+			AddRow("public", "users", "users_pkey", "PRIMARY KEY (id)").
+			AddRow("public", "orders", "orders_pkey", "PRIMARY KEY (id)"))
+	mock.ExpectQuery("FROM pg_indexes").
+		WillReturnRows(sqlmock.NewRows([]string{"schemaname", "tablename", "indexname", "indexdef"}).
+			AddRow("public", "users", "users_pkey", "CREATE UNIQUE INDEX users_pkey ON public.users USING btree (id)").
+			AddRow("public", "orders", "orders_pkey", "CREATE UNIQUE INDEX orders_pkey ON public.orders USING btree (id)").
+			AddRow("public", "orders", "orders_user_id_idx", "CREATE INDEX orders_user_id_idx ON public.orders USING btree (user_id)"))
+	mock.ExpectQuery("JOIN pg_enum").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "typname", "enumlabel"}).
+			AddRow("public", "mood", "sad").
+			AddRow("public", "mood", "ok").
+			AddRow("public", "mood", "happy"))
+	mock.ExpectQuery("FROM pg_proc").
+		WillReturnRows(sqlmock.NewRows([]string{"nspname", "proname", "args", "def"}).
+			AddRow("public", "add", "a integer, b integer", `CREATE OR REPLACE FUNCTION public.add(a integer, b integer)
+ RETURNS integer
+ LANGUAGE sql
+AS $function$SELECT a + b$function$
+`))
+
+	pgdump.DumpSchema(t, db)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
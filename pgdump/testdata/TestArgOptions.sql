@@ -0,0 +1,14 @@
+-- query --
+INSERT INTO users (id, email, password, created_at) VALUES ($1, $2, $3, $4)
+
+-- args --
+{
+ "$1": "95a4ddd9-0000-4000-8000-f47ee1d78daf",
+ "$2": "john.appleseed@mail.com",
+ "$3": "[REDACTED]",
+ "$4": {
+  "type": "time.Time",
+  "value": "2026-10-19T03:14:58.52025475Z"
+ }
+}
+
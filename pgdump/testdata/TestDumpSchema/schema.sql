@@ -0,0 +1,28 @@
+-- table public.orders --
+id bigint NOT NULL DEFAULT nextval('orders_id_seq'::regclass)
+user_id bigint NOT NULL
+
+CONSTRAINT orders_pkey PRIMARY KEY (id)
+CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id)
+CREATE UNIQUE INDEX orders_pkey ON public.orders USING btree (id)
+CREATE INDEX orders_user_id_idx ON public.orders USING btree (user_id)
+
+-- table public.users --
+id bigint NOT NULL DEFAULT nextval('users_id_seq'::regclass)
+name character varying(255) NOT NULL
+mood public.mood DEFAULT 'ok'::public.mood
+
+CONSTRAINT users_pkey PRIMARY KEY (id)
+CREATE UNIQUE INDEX users_pkey ON public.users USING btree (id)
+
+-- enum public.mood --
+sad
+ok
+happy
+
+-- function public.add(a integer, b integer) --
+CREATE OR REPLACE FUNCTION public.add(a integer, b integer)
+ RETURNS integer
+ LANGUAGE sql
+AS $function$SELECT a + b$function$
+
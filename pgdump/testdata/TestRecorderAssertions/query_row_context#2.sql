@@ -0,0 +1,8 @@
+-- query --
+SELECT * FROM users WHERE id = $1
+
+-- args --
+{
+ "$1": 1
+}
+
@@ -0,0 +1,8 @@
+-- query --
+SELECT * FROM users WHERE name = $1
+
+-- args --
+{
+ "$1": "Alice"
+}
+
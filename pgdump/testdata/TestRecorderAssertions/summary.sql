@@ -0,0 +1,4 @@
+-- summary --
+2 SELECT * FROM users WHERE id = $1
+1 SELECT * FROM users WHERE name = $1
+
@@ -0,0 +1,13 @@
+-- query --
+SELECT u.id, u.name FROM users u JOIN orders o ON o.user_id = u.id WHERE u.id = $1
+
+-- args --
+{
+ "$1": 1
+}
+
+-- plan --
+Nested Loop (Inner)
+  Index Scan on users using users_pkey
+  Seq Scan on orders
+
@@ -0,0 +1,18 @@
+-- exec_context --
+INSERT INTO users (id, password) VALUES ($1, $2)
+
+-- args --
+{
+ "$1": 1792379698525377399,
+ "$2": "[REDACTED]"
+}
+
+-- exec_context --
+UPDATE users SET password = $1 WHERE id = $2
+
+-- args --
+{
+ "$1": "[REDACTED]",
+ "$2": 1
+}
+
@@ -0,0 +1,8 @@
+-- query --
+INSERT INTO users (name) VALUES ($1)
+
+-- args --
+{
+ "$1": "Bob"
+}
+
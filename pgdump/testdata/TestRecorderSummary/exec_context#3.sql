@@ -0,0 +1,9 @@
+-- query --
+INSERT INTO users (name, bio) VALUES ($1, 'first line
+second line')
+
+-- args --
+{
+ "$1": "Carol"
+}
+
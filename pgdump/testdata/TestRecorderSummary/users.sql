@@ -0,0 +1,5 @@
+-- summary --
+2 INSERT INTO users (name) VALUES ($1)
+1 INSERT INTO users (name, bio) VALUES ($1, 'first line
+	second line')
+
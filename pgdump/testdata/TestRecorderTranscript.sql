@@ -0,0 +1,28 @@
+-- begin --
+-- exec_context --
+INSERT INTO users (name) VALUES ($1)
+
+-- args --
+{
+ "$1": "Alice"
+}
+
+-- exec_context --
+INSERT INTO users (name) VALUES ($1)
+
+-- args --
+{
+ "$1": "Alice"
+}
+
+-- error --
+duplicate key value violates unique constraint "users_name_key"
+
+-- rollback --
+-- query_row_context --
+SELECT count(*) FROM users
+
+-- summary --
+2 INSERT INTO users (name) VALUES ($1)
+1 SELECT count(*) FROM users
+
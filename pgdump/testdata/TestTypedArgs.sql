@@ -0,0 +1,28 @@
+-- query --
+UPDATE users SET avatar = $1, thumbnail = $2, created_at = $3, deleted_at = $4, status = $6 WHERE id = $5
+
+-- args --
+{
+ "$1": {
+  "type": "[]uint8",
+  "value": "1024 bytes, sha256:49abd65bbf7f7e40"
+ },
+ "$2": {
+  "type": "[]uint8",
+  "value": "\\x7468756d62"
+ },
+ "$3": {
+  "type": "time.Time",
+  "value": "2024-05-22T22:06:30Z"
+ },
+ "$4": {
+  "type": "*time.Time",
+  "value": null
+ },
+ "$5": 1,
+ "$6": {
+  "type": "pgdump_test.status",
+  "value": "ACTIVE"
+ }
+}
+
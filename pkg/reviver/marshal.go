@@ -1,18 +1,32 @@
 package reviver
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+)
 
 // Marshal takes an arbitrary value and applies a function to each key-value pair.
+// The numbers are passed to the function as json.Number, not float64, so that
+// the large integers are written without losing precision. Functions that
+// check for numbers should use json.Number, or return the value unchanged.
 func Marshal(a any, fn func(k []string, v any) (any, error)) ([]byte, error) {
 	b, err := json.Marshal(a)
 	if err != nil {
 		return nil, err
 	}
 
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
 	var c any
-	if err := Unmarshal(b, &c, fn); err != nil {
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+
+	o, err := revive(c, fn)
+	if err != nil {
 		return nil, err
 	}
 
-	return json.Marshal(c)
+	return json.Marshal(o)
 }
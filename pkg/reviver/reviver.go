@@ -15,6 +15,28 @@ func Unmarshal(b []byte, t any, fn func(k []string, v any) (any, error)) error {
 		return err
 	}
 
+	o, err := revive(a, fn)
+	if err != nil {
+		return err
+	}
+
+	// Reduce unnecessary marshal/unmarshall-ing.
+	if v, ok := t.(*map[string]any); ok {
+		*v = o.(map[string]any)
+		return nil
+	}
+
+	b, err = json.Marshal(o)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, t)
+}
+
+// revive applies the function to each key-value pair of the decoded JSON,
+// with the children first.
+func revive(a any, fn func(k []string, v any) (any, error)) (any, error) {
 	var recurse func([]string, any) (any, error)
 	recurse = func(p []string, a any) (any, error) {
 		switch m := a.(type) {
@@ -45,23 +67,7 @@ func Unmarshal(b []byte, t any, fn func(k []string, v any) (any, error)) error {
 		}
 	}
 
-	o, err := recurse(nil, a)
-	if err != nil {
-		return err
-	}
-
-	// Reduce unnecessary marshal/unmarshall-ing.
-	if v, ok := t.(*map[string]any); ok {
-		*v = o.(map[string]any)
-		return nil
-	}
-
-	b, err = json.Marshal(o)
-	if err != nil {
-		return err
-	}
-
-	return json.Unmarshal(b, t)
+	return recurse(nil, a)
 }
 
 type WalkFunc = func(k []string, v any) error
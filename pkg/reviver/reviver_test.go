@@ -163,6 +163,34 @@ func TestReviver(t *testing.T) {
 	})
 }
 
+func TestMarshalNumbers(t *testing.T) {
+	type Order struct {
+		ID    int64   `json:"id"`
+		Total float64 `json:"total"`
+	}
+
+	// The ID exceeds the precision of float64.
+	b, err := reviver.Marshal(Order{ID: 1<<62 + 1, Total: 1.5}, func(k []string, v any) (any, error) {
+		if len(k) == 0 {
+			return v, nil
+		}
+
+		if _, ok := v.(json.Number); !ok {
+			t.Errorf("Marshal() passed %T for %v, want json.Number", v, k)
+		}
+
+		return v, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"id":4611686018427387905,"total":1.5}`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("Marshal() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestWalk(t *testing.T) {
 	t.Run("simple object traversal", func(t *testing.T) {
 		data := map[string]any{
@@ -1,62 +1,253 @@
+// Package sqlformat formats SQL statements with each clause on a new line,
+// without calling external processes.
 package sqlformat
 
 import (
-	"bytes"
-	"errors"
-	"io"
-	"os/exec"
+	"slices"
+	"strings"
+	"unicode"
 )
 
-func Format(stmt string) (string, error) {
-	b, err := sqlformat(stmt)
-	if err != nil {
-		return "", err
+// Token is a token of the statement, as scanned by the dialect parser.
+// Comments are not tokens, and are removed.
+type Token struct {
+	Start, End int // The byte offsets in the statement.
+	Keyword    bool
+}
+
+var (
+	// clauses starts on a new line, indented after the first line.
+	clauses = []string{
+		"FROM",
+		"GROUP",
+		"HAVING",
+		"LIMIT",
+		"OFFSET",
+		"ORDER",
+		"RETURNING",
+		"SET",
+		"VALUES",
+		"WHERE",
+		"WINDOW",
 	}
 
-	return string(b), nil
-}
+	// joins are the keywords that starts a join.
+	joins = []string{
+		"CROSS",
+		"FULL",
+		"INNER",
+		"JOIN",
+		"LEFT",
+		"NATURAL",
+		"RIGHT",
+		"STRAIGHT_JOIN",
+	}
 
-// equals to $ echo 'select 1' | python3 -m sqlparse -r -
-func sqlformat(stmt string) ([]byte, error) {
-	r, w := io.Pipe()
-	defer r.Close()
+	// operators starts the set operations, e.g. UNION ALL.
+	operators = []string{
+		"EXCEPT",
+		"INTERSECT",
+		"UNION",
+	}
 
-	echo := exec.Command("echo", stmt)
-	sqlparse := exec.Command("uvx", "--from", "sqlparse",
-		"sqlformat",
-		"--reindent",           // Reindent statements
-		"--indent_after_first", // Indent after first line of statement
-		"--keywords", "upper",  // Change case of keywords - "upper", "lower" or "capitalize"
-		"--strip-comments", // Remove comments
-		"-")
-	echo.Stdout = w
-	sqlparse.Stdin = r
-	defer w.Close()
+	// keywords are the keywords known to the default scanner in Format.
+	keywords = []string{
+		"ALL", "AND", "AS", "ASC", "BETWEEN", "BY", "CASE", "CONFLICT",
+		"CROSS", "DELETE", "DESC", "DISTINCT", "DO", "DUPLICATE", "ELSE", "END", "EXCEPT",
+		"EXISTS", "FALSE", "FROM", "FULL", "GROUP", "HAVING", "ILIKE", "IN",
+		"INNER", "INSERT", "INTERSECT", "INTO", "IS", "JOIN", "LEFT", "LIKE",
+		"LIMIT", "NATURAL", "NOT", "NOTHING", "NULL", "OFFSET", "ON", "OR",
+		"ORDER", "OUTER", "RETURNING", "RIGHT", "SELECT", "SET", "THEN",
+		"TRUE", "UNION", "UPDATE", "USING", "VALUES", "WHEN", "WHERE",
+		"WINDOW", "WITH",
+	}
+)
 
-	var stderr bytes.Buffer
-	var stdout bytes.Buffer
-	sqlparse.Stderr = &stderr
-	sqlparse.Stdout = &stdout
+// Format formats the statement using a generic scanner.
+// Prefer Indent with the tokens from the dialect parser, which knows all the
+// keywords.
+func Format(stmt string) (string, error) {
+	return Indent(stmt, scan(stmt)), nil
+}
 
-	if err := echo.Start(); err != nil {
-		return nil, err
+// Indent formats the statement from the tokens, e.g.
+//
+//	SELECT *
+//	  FROM users
+//	  WHERE name = $1
+//	    AND id = $2
+//
+// The keywords are upper-cased, and the whitespaces between the tokens are
+// replaced with a single space. Subqueries are indented the same way, relative
+// to the parent query.
+func Indent(stmt string, tokens []Token) string {
+	type scope struct {
+		depth  int // The parentheses depth of the query.
+		indent int
 	}
 
-	if err := sqlparse.Start(); err != nil {
-		return nil, err
+	var (
+		sb       strings.Builder
+		scopes   = []scope{{}}
+		depth    int
+		between  int
+		prevWord string
+	)
+
+	word := func(i int) string {
+		if i < 0 || i >= len(tokens) || !tokens[i].Keyword {
+			return ""
+		}
+
+		t := tokens[i]
+		return strings.ToUpper(stmt[t.Start:t.End])
 	}
 
-	if err := echo.Wait(); err != nil {
-		return nil, err
+	text := func(i int) string {
+		if i < 0 || i >= len(tokens) {
+			return ""
+		}
+
+		return stmt[tokens[i].Start:tokens[i].End]
 	}
-	if err := w.Close(); err != nil {
-		return nil, err
+
+	for i, t := range tokens {
+		s := stmt[t.Start:t.End]
+		w := word(i)
+		if w != "" {
+			s = w
+		}
+
+		cur := scopes[len(scopes)-1]
+
+		// The indent of the new line, or -1 to continue the line.
+		indent := -1
+		if i > 0 && depth == cur.depth {
+			// Keywords that are also functions, e.g. LEFT(...) and VALUES(...).
+			call := text(i+1) == "(" && tokens[i+1].Start == t.End
+
+			switch {
+			case call:
+			case w == "FROM":
+				// DELETE FROM and IS DISTINCT FROM.
+				if prevWord != "DELETE" && prevWord != "DISTINCT" {
+					indent = cur.indent + 2
+				}
+			case slices.Contains(clauses, w):
+				indent = cur.indent + 2
+			case w == "ON" && (word(i+1) == "CONFLICT" || word(i+1) == "DUPLICATE"):
+				indent = cur.indent + 2
+			case slices.Contains(joins, w):
+				// LEFT OUTER JOIN starts at LEFT.
+				if !slices.Contains(joins, prevWord) && prevWord != "OUTER" {
+					indent = cur.indent + 2
+				}
+			case slices.Contains(operators, w):
+				indent = cur.indent
+			case w == "SELECT":
+				// INSERT ... SELECT and WITH ... SELECT, but not UNION SELECT
+				// and subqueries.
+				if text(i-1) != "(" && !slices.Contains(operators, prevWord) && prevWord != "ALL" && prevWord != "DISTINCT" {
+					indent = cur.indent
+				}
+			case w == "BETWEEN":
+				between++
+			case w == "AND" && between > 0:
+				between--
+			case w == "AND", w == "OR":
+				indent = cur.indent + 4
+			}
+		}
+
+		switch {
+		case indent >= 0:
+			sb.WriteString("\n")
+			sb.WriteString(strings.Repeat(" ", indent))
+		case i > 0 && t.Start > tokens[i-1].End:
+			sb.WriteString(" ")
+		}
+		sb.WriteString(s)
+
+		switch s {
+		case "(":
+			depth++
+			if w := word(i + 1); w == "SELECT" || w == "WITH" {
+				scopes = append(scopes, scope{depth: depth, indent: cur.indent + 4})
+			}
+		case ")":
+			if len(scopes) > 1 && depth == cur.depth {
+				scopes = scopes[:len(scopes)-1]
+			}
+			depth--
+		}
+
+		prevWord = w
 	}
-	if err := sqlparse.Wait(); err != nil {
-		return nil, err
+
+	return sb.String()
+}
+
+// scan splits the statement into words, quoted strings and punctuations.
+func scan(stmt string) []Token {
+	var tokens []Token
+
+	isWord := func(r byte) bool {
+		return r == '_' || r == '$' || r >= 0x80 || unicode.IsLetter(rune(r)) || unicode.IsDigit(rune(r))
 	}
-	if b := stderr.Bytes(); len(b) > 0 {
-		return nil, errors.New(string(b))
+
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(stmt[i:], "--"):
+			n := strings.IndexByte(stmt[i:], '\n')
+			if n < 0 {
+				n = len(stmt) - i
+			}
+			i += n
+		case strings.HasPrefix(stmt[i:], "/*"):
+			n := strings.Index(stmt[i+2:], "*/")
+			if n < 0 {
+				i = len(stmt)
+			} else {
+				i += n + 4
+			}
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(stmt) {
+				if stmt[j] == c {
+					// Doubled quotes are escaped.
+					if j+1 < len(stmt) && stmt[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				if stmt[j] == '\\' && c == '\'' {
+					j++
+				}
+				j++
+			}
+			end := min(j+1, len(stmt))
+			tokens = append(tokens, Token{Start: i, End: end})
+			i = end
+		case isWord(c):
+			j := i + 1
+			for j < len(stmt) && isWord(stmt[j]) {
+				j++
+			}
+			tokens = append(tokens, Token{
+				Start:   i,
+				End:     j,
+				Keyword: slices.Contains(keywords, strings.ToUpper(stmt[i:j])),
+			})
+			i = j
+		default:
+			tokens = append(tokens, Token{Start: i, End: i + 1})
+			i++
+		}
 	}
-	return stdout.Bytes(), nil
+
+	return tokens
 }
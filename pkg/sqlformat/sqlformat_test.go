@@ -0,0 +1,70 @@
+package sqlformat_test
+
+import (
+	"testing"
+
+	"github.com/alextanhongpin/testdump/pkg/sqlformat"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		want string
+	}{
+		{
+			name: "where",
+			stmt: "select * from users where name = $1 and id = $2",
+			want: `SELECT *
+  FROM users
+  WHERE name = $1
+    AND id = $2`,
+		},
+		{
+			name: "join",
+			stmt: "select u.id, left(u.name, 1) from users u left outer join orders o on o.user_id = u.id order by u.id limit 1",
+			want: `SELECT u.id, LEFT(u.name, 1)
+  FROM users u
+  LEFT OUTER JOIN orders o ON o.user_id = u.id
+  ORDER BY u.id
+  LIMIT 1`,
+		},
+		{
+			name: "subquery",
+			stmt: "select * from users where id in (select user_id from orders where total between 1 and 10) or id = $1",
+			want: `SELECT *
+  FROM users
+  WHERE id IN (SELECT user_id
+      FROM orders
+      WHERE total BETWEEN 1 AND 10)
+    OR id = $1`,
+		},
+		{
+			name: "comments and whitespaces",
+			stmt: "-- users\nselect  *\n\tfrom users /* all */ where name = 'it''s'",
+			want: `SELECT *
+  FROM users
+  WHERE name = 'it''s'`,
+		},
+		{
+			name: "insert",
+			stmt: "insert into users (name) values ($1) on conflict (name) do nothing returning id",
+			want: `INSERT INTO users (name)
+  VALUES ($1)
+  ON CONFLICT (name) DO NOTHING
+  RETURNING id`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sqlformat.Format(tt.stmt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\n\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
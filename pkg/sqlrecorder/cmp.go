@@ -0,0 +1,50 @@
+package sqlrecorder
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// ignoreMapEntries ignores the map entries with any of the keys, e.g. the
+// args.
+func ignoreMapEntries(keys ...string) cmp.Option {
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	return cmpopts.IgnoreMapEntries(func(k string, v any) bool {
+		for _, key := range keys {
+			if key == k {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// ignoreMapValues ignores the map entries with the string values that fully
+// matches any of the patterns.
+func ignoreMapValues(patterns ...string) cmp.Option {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = regexp.MustCompile(`^(?:` + p + `)$`)
+	}
+
+	return cmpopts.IgnoreMapEntries(func(k string, v any) bool {
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+
+		for _, re := range res {
+			if re.MatchString(s) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
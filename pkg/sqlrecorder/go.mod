@@ -0,0 +1,11 @@
+module github.com/alextanhongpin/testdump/pkg/sqlrecorder
+
+go 1.24.0
+
+require (
+	github.com/alextanhongpin/testdump/pkg/diff v0.0.0-20260202055853-a19b226ed7bf
+	github.com/alextanhongpin/testdump/pkg/file v0.0.0-20260202055853-a19b226ed7bf
+	github.com/alextanhongpin/testdump/pkg/snapshot v0.0.0-20260202055853-a19b226ed7bf
+	github.com/google/go-cmp v0.7.0
+	golang.org/x/tools v0.41.0
+)
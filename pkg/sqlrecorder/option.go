@@ -0,0 +1,164 @@
+package sqlrecorder
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const env = "TESTDUMP"
+
+// Patterns for IgnoreArgPatterns.
+const (
+	UUIDPattern      = `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
+	TimestampPattern = `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`
+)
+
+// Options are the options shared by the SQL dumpers.
+type Options struct {
+	File         string
+	Env          string
+	Colors       bool
+	CmpOpts      []cmp.Option
+	Transformers []func(*SQL) error
+	Transcript   bool
+
+	// Explain returns the plan shape of the query, when set.
+	Explain func(ctx context.Context, query string, args ...any) (string, error)
+
+	// Recorder assertions.
+	MaxQueries     int // Negative for no limit.
+	DetectNPlusOne bool
+	Summary        bool
+
+	queryOpts []queryOptions
+}
+
+// NewOptions returns the options with the defaults.
+func NewOptions(opts ...Option) *Options {
+	o := &Options{
+		Colors:     true,
+		Env:        env,
+		MaxQueries: -1,
+	}
+
+	return o.Apply(opts...)
+}
+
+// Apply applies the options in order.
+func (o *Options) Apply(opts ...Option) *Options {
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Overwrite reports whether the snapshot should be overwritten, as set by
+// the environment variable Env.
+func (o *Options) Overwrite() bool {
+	t, _ := strconv.ParseBool(os.Getenv(o.Env))
+	return t
+}
+
+type Option func(o *Options)
+
+func File(file string) Option {
+	return func(o *Options) {
+		o.File = file
+	}
+}
+
+func Env(env string) Option {
+	return func(o *Options) {
+		o.Env = env
+	}
+}
+
+func Colors(colors bool) Option {
+	return func(o *Options) {
+		o.Colors = colors
+	}
+}
+
+func IgnoreArgs(args ...string) Option {
+	return func(o *Options) {
+		o.CmpOpts = append(o.CmpOpts, ignoreMapEntries(args...))
+	}
+}
+
+// IgnoreArgPatterns ignores the args with values that fully matches any of
+// the patterns, e.g. UUIDPattern and TimestampPattern.
+func IgnoreArgPatterns(patterns ...string) Option {
+	return func(o *Options) {
+		o.CmpOpts = append(o.CmpOpts, ignoreMapValues(patterns...))
+	}
+}
+
+type queryOptions struct {
+	query string
+	opts  []Option
+}
+
+// ForQuery applies the options only to the queries with the same fingerprint
+// as the query, e.g. to ignore the args of one of the queries recorded by the
+// Recorder.
+func ForQuery(query string, opts ...Option) Option {
+	return func(o *Options) {
+		o.queryOpts = append(o.queryOpts, queryOptions{query: query, opts: opts})
+	}
+}
+
+// AsTranscript writes the calls recorded by the Recorder into a single file per
+// test, in the order they are made, together with the errors and the
+// transaction markers.
+// The options set with SetOptionsAt only applies to the entry at the index.
+func AsTranscript() Option {
+	return func(o *Options) {
+		o.Transcript = true
+	}
+}
+
+// Explain writes the plan returned by fn for each query recorded by the
+// Recorder to the plan section.
+// The dumpers provide their own Explain, which runs the EXPLAIN of the
+// database.
+func Explain(fn func(ctx context.Context, query string, args ...any) (string, error)) Option {
+	return func(o *Options) {
+		o.Explain = fn
+	}
+}
+
+// MaxQueries fails the test when the Recorder records more than n queries.
+// Transaction markers are not counted.
+func MaxQueries(n int) Option {
+	return func(o *Options) {
+		o.MaxQueries = n
+	}
+}
+
+// DetectNPlusOne fails the test when the Recorder records the same SELECT,
+// by fingerprint, at least 3 times with different args, e.g. when querying in
+// a loop.
+func DetectNPlusOne() Option {
+	return func(o *Options) {
+		o.DetectNPlusOne = true
+	}
+}
+
+// Summary writes the number of calls of each query, by fingerprint, in the
+// order of the first call, so that the added queries shows up in the diff.
+// The summary is written to the transcript, or to a separate summary.sql
+// file, named after File when provided.
+func Summary() Option {
+	return func(o *Options) {
+		o.Summary = true
+	}
+}
+
+func Transformers(ts ...func(*SQL) error) Option {
+	return func(o *Options) {
+		o.Transformers = append(o.Transformers, ts...)
+	}
+}
@@ -0,0 +1,335 @@
+package sqlrecorder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// DBTX is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Prepare(query string) (*sql.Stmt, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txBeginner is implemented by *sql.DB and *sql.Conn.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Recorder logs the query and args.
+type Recorder struct {
+	d        *Dialect
+	entries  []Entry
+	id       int
+	opt      *Options
+	opts     []Option
+	optsByID map[int][]Option
+	seen     map[string]int
+	t        *testing.T
+}
+
+// NewRecorder returns a recorder that dumps the calls when the test
+// finishes.
+func (d *Dialect) NewRecorder(t *testing.T, opts ...Option) *Recorder {
+	r := &Recorder{
+		d:        d,
+		t:        t,
+		opt:      NewOptions(opts...),
+		opts:     opts,
+		optsByID: make(map[int][]Option),
+		seen:     make(map[string]int),
+	}
+	t.Cleanup(r.dump)
+	return r
+}
+
+// SetOptionsAt sets the options for the id-th call.
+func (r *Recorder) SetOptionsAt(id int, opts ...Option) {
+	r.optsByID[id] = opts
+}
+
+// Record records the call.
+// Calls without query are transaction markers, and are only written to the
+// transcript.
+func (r *Recorder) Record(method, query string, args ...any) {
+	r.RecordContext(context.Background(), method, query, args...)
+}
+
+// RecordContext is similar to Record, with the context of the call, which is
+// used to explain the query.
+func (r *Recorder) RecordContext(ctx context.Context, method, query string, args ...any) {
+	if query != "" {
+		fileName := method
+		r.seen[fileName]++
+		fileName = fmt.Sprintf("%s#%d", fileName, r.seen[fileName])
+
+		r.optsByID[r.id] = append(r.optsByID[r.id], File(fileName))
+	}
+
+	e := Entry{
+		Method: method,
+		Query:  query,
+		Args:   args,
+	}
+	if explain := r.opt.Explain; explain != nil && query != "" {
+		plan, err := explain(ctx, query, args...)
+		if err != nil {
+			r.t.Error(err)
+		}
+		e.Plan = plan
+	}
+
+	r.entries = append(r.entries, e)
+	r.id++
+}
+
+// RecordError records the error of the last call.
+func (r *Recorder) RecordError(err error) {
+	if err == nil || len(r.entries) == 0 {
+		return
+	}
+
+	r.entries[len(r.entries)-1].Error = err.Error()
+}
+
+func (r *Recorder) DB(db DBTX) *DB {
+	return NewDBRecorder(db, r)
+}
+
+func (r *Recorder) dump() {
+	r.t.Helper()
+
+	opt := r.opt
+	if err := r.d.checkQueries(r.entries, opt); err != nil {
+		r.t.Error(err)
+	}
+
+	var summary []QueryCount
+	if opt.Summary {
+		f, err := r.d.fingerprint(r.entries)
+		if err == nil {
+			summary, err = r.d.summary(f)
+		}
+		if err != nil {
+			r.t.Error(err)
+			return
+		}
+	}
+
+	if opt.Transcript {
+		tr := &Transcript{
+			Entries: r.entries,
+			Summary: summary,
+		}
+		if err := r.d.dumpTranscript(r.t, tr, r.optsByID, r.opts...); err != nil {
+			r.t.Error(err)
+		}
+
+		return
+	}
+
+	for i, e := range r.entries {
+		if e.Query == "" {
+			continue
+		}
+
+		dump := &SQL{
+			Args:  e.Args,
+			Query: e.Query,
+			Plan:  e.Plan,
+		}
+		r.d.Dump(r.t, dump, append(r.opts, r.optsByID[i]...)...)
+	}
+
+	if opt.Summary {
+		if err := r.d.dumpSummary(r.t, summary, r.opts...); err != nil {
+			r.t.Error(err)
+		}
+	}
+}
+
+// QueryRecorder records the calls made to the DB, e.g. Recorder.
+type QueryRecorder interface {
+	Record(method, query string, args ...any)
+}
+
+// contextRecorder is implemented by recorders that records the context of
+// the call, e.g. Recorder.
+type contextRecorder interface {
+	RecordContext(ctx context.Context, method, query string, args ...any)
+}
+
+// errorRecorder is implemented by recorders that records the error of the
+// call, e.g. Recorder.
+type errorRecorder interface {
+	RecordError(err error)
+}
+
+var _ DBTX = (*DB)(nil)
+
+type DB struct {
+	rec QueryRecorder
+	db  DBTX
+}
+
+func NewDBRecorder(db DBTX, rec QueryRecorder) *DB {
+	return &DB{db: db, rec: rec}
+}
+
+func (d *DB) SetDB(db DBTX) {
+	d.db = db
+}
+
+func (d *DB) record(ctx context.Context, method, query string, args ...any) {
+	if rec, ok := d.rec.(contextRecorder); ok {
+		rec.RecordContext(ctx, method, query, args...)
+		return
+	}
+
+	d.rec.Record(method, query, args...)
+}
+
+func (d *DB) recordError(err error) {
+	if rec, ok := d.rec.(errorRecorder); ok {
+		rec.RecordError(err)
+	}
+}
+
+func (d *DB) Exec(query string, args ...any) (sql.Result, error) {
+	d.rec.Record("exec", query, args...)
+
+	res, err := d.db.Exec(query, args...)
+	d.recordError(err)
+
+	return res, err
+}
+
+func (d *DB) Prepare(query string) (*sql.Stmt, error) {
+	d.rec.Record("prepare", query)
+
+	stmt, err := d.db.Prepare(query)
+	d.recordError(err)
+
+	return stmt, err
+}
+
+func (d *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	d.rec.Record("query", query, args...)
+
+	rows, err := d.db.Query(query, args...)
+	d.recordError(err)
+
+	return rows, err
+}
+
+func (d *DB) QueryRow(query string, args ...any) *sql.Row {
+	d.rec.Record("query_row", query, args...)
+
+	row := d.db.QueryRow(query, args...)
+	d.recordError(row.Err())
+
+	return row
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	d.record(ctx, "exec_context", query, args...)
+
+	res, err := d.db.ExecContext(ctx, query, args...)
+	d.recordError(err)
+
+	return res, err
+}
+
+func (d *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	d.record(ctx, "prepare_context", query)
+
+	stmt, err := d.db.PrepareContext(ctx, query)
+	d.recordError(err)
+
+	return stmt, err
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	d.record(ctx, "query_context", query, args...)
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	d.recordError(err)
+
+	return rows, err
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	d.record(ctx, "query_row_context", query, args...)
+
+	row := d.db.QueryRowContext(ctx, query, args...)
+	d.recordError(row.Err())
+
+	return row
+}
+
+// Begin is similar to BeginTx, with the background context.
+func (d *DB) Begin() (*Tx, error) {
+	return d.BeginTx(context.Background(), nil)
+}
+
+// BeginTx starts a transaction, if the db supports it, e.g. *sql.DB.
+// The queries made in the transaction are recorded between the begin, and
+// the commit or rollback markers.
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	b, ok := d.db.(txBeginner)
+	if !ok {
+		return nil, fmt.Errorf("sqlrecorder: %T does not support transactions", d.db)
+	}
+
+	d.record(ctx, methodBegin, "")
+
+	tx, err := b.BeginTx(ctx, opts)
+	d.recordError(err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{
+		DB: NewDBRecorder(tx, d.rec),
+		tx: tx,
+	}, nil
+}
+
+// Tx records the queries made in the transaction.
+type Tx struct {
+	*DB
+	tx *sql.Tx
+}
+
+// Commit commits the transaction.
+func (t *Tx) Commit() error {
+	return t.end(methodCommit, t.tx.Commit)
+}
+
+// Rollback aborts the transaction.
+// Rollback after Commit is not recorded, so that it can be deferred.
+func (t *Tx) Rollback() error {
+	return t.end(methodRollback, t.tx.Rollback)
+}
+
+func (t *Tx) end(method string, fn func() error) error {
+	err := fn()
+	if errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+
+	t.rec.Record(method, "")
+	t.recordError(err)
+
+	return err
+}
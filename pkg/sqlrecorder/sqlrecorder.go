@@ -0,0 +1,75 @@
+// Package sqlrecorder records the calls made to a database, for the SQL
+// dumpers, e.g. pgdump, mysqldump and sqlitedump.
+//
+// The calls are written as a snapshot each, or as a single transcript,
+// together with the summary of the queries.
+// The parts that depends on the database, e.g. the query fingerprint and the
+// format of the args, are provided by the Dialect.
+// The options shared by the dumpers, e.g. File and ForQuery, are defined here,
+// and re-exported by each dumper.
+package sqlrecorder
+
+import "testing"
+
+// SQL is the query and args of a single call.
+type SQL struct {
+	Query string
+	Args  []any
+	Plan  string // The plan shape, see Explain.
+}
+
+// Dialect is the database specific part of the recorder.
+type Dialect struct {
+	// Name is the name of the dumper, used as the prefix of the errors.
+	Name string
+
+	// Dump writes a single call, when the calls are not written as a
+	// transcript.
+	Dump func(t *testing.T, s *SQL, opts ...Option)
+
+	// Fingerprint returns the same key for the queries that only differs by
+	// the literals and the bind variables.
+	Fingerprint func(query string) (string, error)
+
+	// CompareQuery checks if two queries are equal, ignoring variables.
+	CompareQuery func(a, b string) (bool, error)
+
+	// Normalize returns the query as written to the snapshot.
+	Normalize func(query string) (string, error)
+
+	// IsSelect checks if the query is a SELECT, since only the repeated
+	// SELECTs are reported as N+1 queries.
+	IsSelect func(query string) (bool, error)
+
+	// ArgsMap converts the args into a map keyed by the placeholder, e.g. `$1`.
+	ArgsMap func(args []any) (map[string]any, error)
+
+	// ReadArgs reads the args written from ArgsMap.
+	ReadArgs func(b []byte) ([]any, error)
+}
+
+// QueryOptions returns the options for the query, followed by the options
+// set with ForQuery for the query.
+func (d *Dialect) QueryOptions(query string, opts ...Option) *Options {
+	o := NewOptions(opts...)
+
+	return o.Apply(d.forQuery(o, query)...)
+}
+
+// forQuery returns the options set with ForQuery for the query.
+func (d *Dialect) forQuery(o *Options, query string) []Option {
+	var res []Option
+	for _, q := range o.queryOpts {
+		if ok, err := d.CompareQuery(q.query, query); err == nil && ok {
+			res = append(res, q.opts...)
+		}
+	}
+
+	return res
+}
+
+func appendNewLine(b []byte) []byte {
+	b = append(b, '\n')
+	b = append(b, '\n')
+	return b
+}
@@ -0,0 +1,109 @@
+package sqlrecorder_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alextanhongpin/testdump/pkg/sqlrecorder"
+	"github.com/google/go-cmp/cmp"
+)
+
+// dialect compares the queries as is, with the args keyed by position.
+var dialect = &sqlrecorder.Dialect{
+	Name: "test",
+	Fingerprint: func(query string) (string, error) {
+		return strings.ToLower(query), nil
+	},
+	CompareQuery: func(a, b string) (bool, error) {
+		return strings.EqualFold(a, b), nil
+	},
+	Normalize: func(query string) (string, error) {
+		return query, nil
+	},
+	ArgsMap: func(args []any) (map[string]any, error) {
+		m := make(map[string]any)
+		for i, v := range args {
+			m[fmt.Sprintf("$%d", i+1)] = v
+		}
+
+		return m, nil
+	},
+	ReadArgs: func(b []byte) ([]any, error) {
+		var m map[string]any
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+
+		args := make([]any, len(m))
+		for i := range args {
+			args[i] = m[fmt.Sprintf("$%d", i+1)]
+		}
+
+		return args, nil
+	},
+}
+
+func TestTranscript(t *testing.T) {
+	tr := &sqlrecorder.Transcript{
+		Entries: []sqlrecorder.Entry{
+			{Method: "begin"},
+			{Method: "exec_context", Query: "insert into users (name) values ($1)", Args: []any{"John"}},
+			{Method: "query_context", Query: "select * from users", Error: "sql: no rows", Plan: "Seq Scan on users"},
+			{Method: "commit"},
+		},
+		Summary: []sqlrecorder.QueryCount{
+			{Count: 1, Query: "insert into users (name) values ($1)"},
+			{Count: 2, Query: "select *\nfrom users"},
+		},
+	}
+
+	b, err := dialect.WriteTranscript(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := dialect.ReadTranscript(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(tr, got); diff != "" {
+		t.Errorf("transcript mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadTranscriptError(t *testing.T) {
+	b := []byte("-- args --\n{}\n")
+	if _, err := dialect.ReadTranscript(b); err == nil {
+		t.Error("want error, got nil")
+	}
+}
+
+func TestQueryOptions(t *testing.T) {
+	opts := []sqlrecorder.Option{
+		sqlrecorder.File("users"),
+		sqlrecorder.ForQuery("SELECT * FROM users", sqlrecorder.File("select"), sqlrecorder.Colors(false)),
+	}
+
+	t.Run("match", func(t *testing.T) {
+		o := dialect.QueryOptions("select * from users", opts...)
+		if want, got := "select", o.File; want != got {
+			t.Errorf("want %s, got %s", want, got)
+		}
+		if o.Colors {
+			t.Error("want no colors")
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		o := dialect.QueryOptions("select * from accounts", opts...)
+		if want, got := "users", o.File; want != got {
+			t.Errorf("want %s, got %s", want, got)
+		}
+		if !o.Colors {
+			t.Error("want colors")
+		}
+	})
+}
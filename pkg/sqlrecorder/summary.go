@@ -0,0 +1,236 @@
+package sqlrecorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alextanhongpin/testdump/pkg/diff"
+	"github.com/alextanhongpin/testdump/pkg/file"
+	"github.com/alextanhongpin/testdump/pkg/snapshot"
+	"golang.org/x/tools/txtar"
+)
+
+const summarySection = "summary"
+
+// nPlusOneCalls is the minimum number of calls of the same SELECT, with
+// different args, to be reported as N+1 query.
+const nPlusOneCalls = 3
+
+// QueryCount is the number of calls of the queries with the same
+// fingerprint.
+type QueryCount struct {
+	Count int
+	Query string // The first query with the fingerprint.
+}
+
+// fingerprinted is the entries grouped by the query fingerprint, in the order
+// of the first call.
+type fingerprinted struct {
+	keys    []string
+	entries map[string][]Entry
+}
+
+func (d *Dialect) fingerprint(entries []Entry) (*fingerprinted, error) {
+	f := &fingerprinted{
+		entries: make(map[string][]Entry),
+	}
+	for _, e := range entries {
+		// Transaction markers.
+		if e.Query == "" {
+			continue
+		}
+
+		key, err := d.Fingerprint(e.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := f.entries[key]; !ok {
+			f.keys = append(f.keys, key)
+		}
+		f.entries[key] = append(f.entries[key], e)
+	}
+
+	return f, nil
+}
+
+func (d *Dialect) summary(f *fingerprinted) ([]QueryCount, error) {
+	res := make([]QueryCount, len(f.keys))
+	for i, key := range f.keys {
+		q, err := d.Normalize(f.entries[key][0].Query)
+		if err != nil {
+			return nil, err
+		}
+
+		res[i] = QueryCount{
+			Count: len(f.entries[key]),
+			Query: q,
+		}
+	}
+
+	return res, nil
+}
+
+// checkQueries checks the query budget and the N+1 queries.
+func (d *Dialect) checkQueries(entries []Entry, opt *Options) error {
+	f, err := d.fingerprint(entries)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if opt.MaxQueries >= 0 {
+		var n int
+		for _, key := range f.keys {
+			n += len(f.entries[key])
+		}
+		if n > opt.MaxQueries {
+			errs = append(errs, fmt.Errorf("%s: got %d queries, want at most %d", d.Name, n, opt.MaxQueries))
+		}
+	}
+
+	if opt.DetectNPlusOne {
+		for _, key := range f.keys {
+			entries := f.entries[key]
+			if len(entries) < nPlusOneCalls {
+				continue
+			}
+
+			ok, err := d.IsSelect(entries[0].Query)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			if n := distinctArgs(entries); n > 1 {
+				errs = append(errs, fmt.Errorf("%s: N+1 query, called %d times with %d different args: %s", d.Name, len(entries), n, entries[0].Query))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func distinctArgs(entries []Entry) int {
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		b, _ := json.Marshal(e.Args)
+		seen[string(b)] = true
+	}
+
+	return len(seen)
+}
+
+// dumpSummary writes the summary into a separate file, when the calls are not
+// written as a transcript.
+func (d *Dialect) dumpSummary(t *testing.T, summary []QueryCount, opts ...Option) error {
+	opt := NewOptions(opts...)
+
+	name := opt.File
+	if name == "" {
+		name = "summary"
+	}
+
+	path := filepath.Join("testdata", t.Name(), fmt.Sprintf("%s.sql", name))
+	f, err := file.New(path, opt.Overwrite())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := &summaryEncoder{d: d}
+	return snapshot.Snapshot(f, enc, &summaryComparer{colors: opt.Colors}, summary)
+}
+
+type summaryEncoder struct {
+	d *Dialect
+}
+
+func (e *summaryEncoder) Marshal(v any) ([]byte, error) {
+	arc := &txtar.Archive{
+		Files: []txtar.File{writeSummary(v.([]QueryCount))},
+	}
+
+	return txtar.Format(arc), nil
+}
+
+func (e *summaryEncoder) Unmarshal(b []byte) (any, error) {
+	for _, f := range txtar.Parse(b).Files {
+		if f.Name == summarySection {
+			return e.d.readSummary(bytes.TrimSpace(f.Data))
+		}
+	}
+
+	return []QueryCount(nil), nil
+}
+
+type summaryComparer struct {
+	colors bool
+}
+
+func (c *summaryComparer) Compare(a, b any) error {
+	comparer := diff.Text
+	if c.colors {
+		comparer = diff.ANSI
+	}
+
+	if err := comparer(a, b); err != nil {
+		return fmt.Errorf("Summary: %w", err)
+	}
+
+	return nil
+}
+
+// writeSummary writes each query with the number of calls, e.g.
+//
+//	2 SELECT * FROM users WHERE id = $1
+//
+// The following lines of a multi-line query are indented with a tab.
+func writeSummary(summary []QueryCount) txtar.File {
+	lines := make([]string, len(summary))
+	for i, s := range summary {
+		lines[i] = fmt.Sprintf("%d %s", s.Count, strings.ReplaceAll(s.Query, "\n", "\n\t"))
+	}
+
+	return txtar.File{
+		Name: summarySection,
+		Data: appendNewLine([]byte(strings.Join(lines, "\n"))),
+	}
+}
+
+func (d *Dialect) readSummary(b []byte) ([]QueryCount, error) {
+	var res []QueryCount
+	for _, line := range strings.Split(string(b), "\n") {
+		// The following lines of a multi-line query.
+		if rest, ok := strings.CutPrefix(line, "\t"); ok && len(res) > 0 {
+			res[len(res)-1].Query += "\n" + rest
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		count, query, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid summary: %s", d.Name, line)
+		}
+
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, QueryCount{Count: n, Query: query})
+	}
+
+	return res, nil
+}
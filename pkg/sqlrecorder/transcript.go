@@ -0,0 +1,331 @@
+package sqlrecorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/alextanhongpin/testdump/pkg/diff"
+	"github.com/alextanhongpin/testdump/pkg/file"
+	"github.com/alextanhongpin/testdump/pkg/snapshot"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/txtar"
+)
+
+const (
+	argsSection  = "args"
+	errorSection = "error"
+	planSection  = "plan"
+)
+
+// Transaction markers.
+const (
+	methodBegin    = "begin"
+	methodCommit   = "commit"
+	methodRollback = "rollback"
+)
+
+// Transcript is the ordered list of calls made in a test.
+type Transcript struct {
+	Entries []Entry
+	Summary []QueryCount // Optional.
+}
+
+// Entry is a single call in the transcript.
+// Transaction markers, e.g. begin, commit and rollback, have no query.
+type Entry struct {
+	Method string
+	Query  string
+	Args   []any
+	Error  string
+	Plan   string
+}
+
+// DumpTranscript writes the transcript into a single file.
+func (d *Dialect) DumpTranscript(t *testing.T, tr *Transcript, opts ...Option) {
+	t.Helper()
+
+	if err := d.dumpTranscript(t, tr, nil, opts...); err != nil {
+		t.Error(err)
+	}
+}
+
+// dumpTranscript is similar to Dump, but the options in optsAt are only
+// applied when comparing the entry at the index.
+func (d *Dialect) dumpTranscript(t *testing.T, tr *Transcript, optsAt map[int][]Option, opts ...Option) error {
+	opt := NewOptions(opts...)
+
+	path := filepath.Join("testdata", fmt.Sprintf("%s.sql", filepath.Join(t.Name(), opt.File)))
+	f, err := file.New(path, opt.Overwrite())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// The options set with SetOptionsAt and ForQuery for each entry.
+	cmpOptsAt := make(map[int][]cmp.Option)
+	marshalFnsAt := make(map[int][]func(*SQL) error)
+	for i, e := range tr.Entries {
+		o := NewOptions(append(optsAt[i][:len(optsAt[i]):len(optsAt[i])], d.forQuery(opt, e.Query)...)...)
+		cmpOptsAt[i] = o.CmpOpts
+		marshalFnsAt[i] = o.Transformers
+	}
+
+	enc := &transcriptEncoder{
+		d:            d,
+		marshalFns:   opt.Transformers,
+		marshalFnsAt: marshalFnsAt,
+	}
+	c := &transcriptComparer{
+		d:      d,
+		opts:   opt.CmpOpts,
+		optsAt: cmpOptsAt,
+		colors: opt.Colors,
+	}
+
+	return snapshot.Snapshot(f, enc, c, tr)
+}
+
+type transcriptEncoder struct {
+	d            *Dialect
+	marshalFns   []func(*SQL) error
+	marshalFnsAt map[int][]func(*SQL) error
+}
+
+func (e *transcriptEncoder) Marshal(v any) ([]byte, error) {
+	return e.d.writeTranscript(v.(*Transcript), e.marshalFns, e.marshalFnsAt)
+}
+
+func (e *transcriptEncoder) Unmarshal(b []byte) (any, error) {
+	return e.d.ReadTranscript(b)
+}
+
+// WriteTranscript writes each entry as a section named after the method,
+// followed by the optional args and error sections.
+func (d *Dialect) WriteTranscript(tr *Transcript, transformers ...func(*SQL) error) ([]byte, error) {
+	return d.writeTranscript(tr, transformers, nil)
+}
+
+// writeTranscript is similar to WriteTranscript, but the transformers in
+// transformersAt are only applied to the entry at the index.
+func (d *Dialect) writeTranscript(tr *Transcript, transformers []func(*SQL) error, transformersAt map[int][]func(*SQL) error) ([]byte, error) {
+	arc := new(txtar.Archive)
+	for i, e := range tr.Entries {
+		// Transaction markers.
+		if e.Query == "" {
+			arc.Files = append(arc.Files, txtar.File{Name: e.Method})
+			continue
+		}
+
+		q, err := d.Normalize(e.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		s := &SQL{Query: q, Args: e.Args}
+		for _, transform := range append(transformers[:len(transformers):len(transformers)], transformersAt[i]...) {
+			if err := transform(s); err != nil {
+				return nil, err
+			}
+		}
+
+		arc.Files = append(arc.Files, txtar.File{
+			Name: e.Method,
+			Data: appendNewLine([]byte(s.Query)),
+		})
+
+		if len(s.Args) > 0 {
+			m, err := d.ArgsMap(s.Args)
+			if err != nil {
+				return nil, err
+			}
+
+			a, err := json.MarshalIndent(m, "", " ")
+			if err != nil {
+				return nil, err
+			}
+
+			arc.Files = append(arc.Files, txtar.File{
+				Name: argsSection,
+				Data: appendNewLine(a),
+			})
+		}
+
+		if e.Error != "" {
+			arc.Files = append(arc.Files, txtar.File{
+				Name: errorSection,
+				Data: appendNewLine([]byte(e.Error)),
+			})
+		}
+
+		if e.Plan != "" {
+			arc.Files = append(arc.Files, txtar.File{
+				Name: planSection,
+				Data: appendNewLine([]byte(e.Plan)),
+			})
+		}
+	}
+
+	if len(tr.Summary) > 0 {
+		arc.Files = append(arc.Files, writeSummary(tr.Summary))
+	}
+
+	return txtar.Format(arc), nil
+}
+
+// ReadTranscript reads the entries written by WriteTranscript.
+func (d *Dialect) ReadTranscript(b []byte) (*Transcript, error) {
+	tr := new(Transcript)
+
+	arc := txtar.Parse(b)
+	for _, f := range arc.Files {
+		name, data := f.Name, bytes.TrimSpace(f.Data)
+
+		switch name {
+		case summarySection:
+			summary, err := d.readSummary(data)
+			if err != nil {
+				return nil, err
+			}
+			tr.Summary = summary
+		case argsSection, errorSection, planSection:
+			if len(tr.Entries) == 0 {
+				return nil, fmt.Errorf("%s: %s section without query", d.Name, name)
+			}
+
+			e := &tr.Entries[len(tr.Entries)-1]
+			switch name {
+			case errorSection:
+				e.Error = string(data)
+				continue
+			case planSection:
+				e.Plan = string(data)
+				continue
+			}
+
+			args, err := d.ReadArgs(data)
+			if err != nil {
+				return nil, err
+			}
+			e.Args = args
+		default:
+			tr.Entries = append(tr.Entries, Entry{
+				Method: name,
+				Query:  string(data),
+			})
+		}
+	}
+
+	return tr, nil
+}
+
+type transcriptComparer struct {
+	d      *Dialect
+	opts   []cmp.Option
+	optsAt map[int][]cmp.Option
+	colors bool
+}
+
+func (c *transcriptComparer) Compare(a, b any) error {
+	return c.compare(a.(*Transcript), b.(*Transcript))
+}
+
+func (c *transcriptComparer) compare(snapshot, received *Transcript) error {
+	comparer := diff.Text
+	if c.colors {
+		comparer = diff.ANSI
+	}
+
+	// Compare the sequence first, so that the inserted or removed queries
+	// are reported, instead of every entry after them.
+	lhs := steps(snapshot.Entries)
+	rhs := steps(received.Entries)
+	if err := comparer(lhs, rhs, cmp.Comparer(c.d.equal)); err != nil {
+		return fmt.Errorf("Sequence: %w", err)
+	}
+
+	for i := range received.Entries {
+		x, y := snapshot.Entries[i], received.Entries[i]
+
+		lhs, err := c.d.toMap(x.Args)
+		if err != nil {
+			return err
+		}
+		rhs, err := c.d.toMap(y.Args)
+		if err != nil {
+			return err
+		}
+
+		opts := append(c.opts[:len(c.opts):len(c.opts)], c.optsAt[i]...)
+		if err := comparer(lhs, rhs, opts...); err != nil {
+			return fmt.Errorf("Entry #%d %s Args: %w", i+1, y.Method, err)
+		}
+
+		if err := comparer(x.Error, y.Error); err != nil {
+			return fmt.Errorf("Entry #%d %s Error: %w", i+1, y.Method, err)
+		}
+
+		if err := comparer(x.Plan, y.Plan); err != nil {
+			return fmt.Errorf("Entry #%d %s Plan: %w", i+1, y.Method, err)
+		}
+	}
+
+	if err := comparer(snapshot.Summary, received.Summary); err != nil {
+		return fmt.Errorf("Summary: %w", err)
+	}
+
+	return nil
+}
+
+// toMap converts the args into a map for better diff.
+func (d *Dialect) toMap(args []any) (any, error) {
+	m, err := d.ArgsMap(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Marshal/unmarshal to avoid type issues such as
+	// int/float.
+	// In JSON, there's only float.
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var a any
+	if err := json.Unmarshal(b, &a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// step is the method and query of an entry, for the sequence diff.
+type step struct {
+	Method string
+	Query  string
+}
+
+func steps(entries []Entry) []step {
+	res := make([]step, len(entries))
+	for i, e := range entries {
+		res[i] = step{Method: e.Method, Query: e.Query}
+	}
+
+	return res
+}
+
+// equal checks if the steps are equal, ignoring the query formatting.
+func (d *Dialect) equal(s, o step) bool {
+	if s.Method != o.Method {
+		return false
+	}
+	if s.Query == o.Query {
+		return true
+	}
+
+	ok, err := d.CompareQuery(s.Query, o.Query)
+	return err == nil && ok
+}
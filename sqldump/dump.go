@@ -0,0 +1,413 @@
+package sqldump
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alextanhongpin/testdump/pkg/diff"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/tools/txtar"
+)
+
+const (
+	columnsSection = "columns"
+	rowsSection    = "rows"
+)
+
+var d *Dumper
+
+func init() {
+	d = New()
+}
+
+type Dumper struct {
+	opts []Option
+}
+
+func New(opts ...Option) *Dumper {
+	return &Dumper{opts: opts}
+}
+
+// Dump writes the column definitions and the rows as an aligned table, or
+// as CSV.
+func Dump(t *testing.T, r *Result, opts ...Option) {
+	d.Dump(t, r, opts...)
+}
+
+func (d *Dumper) Dump(t *testing.T, r *Result, opts ...Option) {
+	t.Helper()
+
+	opt := newOptions().apply(append(d.opts, opts...)...)
+
+	path := filepath.Join("testdata", fmt.Sprintf("%s.txt", filepath.Join(t.Name(), opt.file)))
+	if err := dumpFile(path, opt, opt.encoder(""), opt.comparer(), r); err != nil {
+		t.Error(err)
+	}
+}
+
+// table is the result as written, since the values are compared as text.
+type table struct {
+	Columns []string // The column definitions.
+	Header  []string
+	Rows    [][]string
+}
+
+// newTable formats the result, without the excluded columns, and with the
+// masked values.
+func newTable(r *Result, name string, exclude []string, masks map[string]string) *table {
+	// matches checks if the column, or the column scoped to the table, is in
+	// the keys.
+	matches := func(keys []string, col string) bool {
+		return slices.Contains(keys, col) || (name != "" && slices.Contains(keys, name+"."+col))
+	}
+	mask := func(col string) (string, bool) {
+		if m, ok := masks[col]; ok {
+			return m, true
+		}
+		if name == "" {
+			return "", false
+		}
+
+		m, ok := masks[name+"."+col]
+		return m, ok
+	}
+
+	t := &table{
+		Rows: make([][]string, len(r.Rows)),
+	}
+
+	var cols []int
+	for i, c := range r.Columns {
+		if matches(exclude, c.Name) {
+			continue
+		}
+
+		cols = append(cols, i)
+		t.Columns = append(t.Columns, c.String())
+		t.Header = append(t.Header, c.Name)
+	}
+
+	for i, row := range r.Rows {
+		t.Rows[i] = make([]string, len(cols))
+		for j, col := range cols {
+			v := row[col]
+			if m, ok := mask(r.Columns[col].Name); ok && v != nil {
+				v = m
+			}
+			t.Rows[i][j] = formatValue(v)
+		}
+	}
+
+	return t
+}
+
+// sortRows sorts the rows by the formatted values, e.g. for tables without
+// primary key, so that the excluded columns do not change the order.
+func (t *table) sortRows() {
+	slices.SortStableFunc(t.Rows, slices.Compare)
+}
+
+// nullValue is the NULL value, similar to COPY in Postgres and MySQL, so
+// that NULL and the string "NULL" are different.
+const nullValue = `\N`
+
+// formatValue formats the value returned by the driver.
+// The backslashes are escaped, so that the strings are different from NULL
+// and the binary values, e.g. `\N` and `\x00`.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return nullValue
+	case []byte:
+		if utf8.Valid(v) {
+			return escapeValue(string(v))
+		}
+
+		return `\x` + hex.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return escapeValue(fmt.Sprint(v))
+	}
+}
+
+func escapeValue(s string) string {
+	return strings.ReplaceAll(s, `\`, `\\`)
+}
+
+type encoder struct {
+	csv      bool
+	table    string
+	exclude  []string
+	masks    map[string]string
+	sortRows bool
+}
+
+func (e *encoder) Marshal(v any) ([]byte, error) {
+	t := newTable(v.(*Result), e.table, e.exclude, e.masks)
+	if e.sortRows {
+		t.sortRows()
+	}
+
+	files, err := writeSections(t, "", e.csv)
+	if err != nil {
+		return nil, err
+	}
+
+	return txtar.Format(&txtar.Archive{Files: files}), nil
+}
+
+func (e *encoder) Unmarshal(b []byte) (any, error) {
+	t := new(table)
+	for _, f := range txtar.Parse(b).Files {
+		if err := readSection(t, f.Name, f.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// writeSections writes the columns and rows sections, with the prefix in the
+// names.
+func writeSections(t *table, prefix string, asCSV bool) ([]txtar.File, error) {
+	var rows []byte
+	if asCSV {
+		var err error
+		rows, err = writeCSV(append([][]string{t.Header}, t.Rows...))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		rows = writeTable(t.Header, t.Rows)
+	}
+
+	return []txtar.File{
+		{
+			Name: prefix + columnsSection,
+			Data: appendNewLine([]byte(strings.Join(t.Columns, "\n"))),
+		},
+		{
+			Name: prefix + rowsSection,
+			Data: append(rows, '\n'),
+		},
+	}, nil
+}
+
+func readSection(t *table, name string, b []byte) error {
+	data := bytes.TrimSpace(b)
+
+	switch name {
+	case columnsSection:
+		if len(data) > 0 {
+			t.Columns = strings.Split(string(data), "\n")
+		}
+	case rowsSection:
+		var rows [][]string
+		if bytes.HasPrefix(data, []byte("|")) {
+			rows = parseTable(data)
+		} else {
+			var err error
+			rows, err = csv.NewReader(bytes.NewReader(data)).ReadAll()
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) > 0 {
+			t.Header, t.Rows = rows[0], rows[1:]
+		}
+	}
+
+	return nil
+}
+
+func writeCSV(rows [][]string) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// writeTable writes the rows as a markdown table, with the columns aligned,
+// e.g.
+//
+//	| id | name  |
+//	|----|-------|
+//	| 1  | alpha |
+func writeTable(header []string, rows [][]string) []byte {
+	lines := append([][]string{header}, rows...)
+
+	widths := make([]int, len(header))
+	for _, line := range lines {
+		for i, cell := range line {
+			widths[i] = max(widths[i], utf8.RuneCountInString(escapeCell(cell)))
+		}
+	}
+
+	var b bytes.Buffer
+	writeLine := func(cells []string) {
+		b.WriteString("|")
+		for i, cell := range cells {
+			cell = escapeCell(cell)
+			b.WriteString(" ")
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeLine(header)
+	b.WriteString("|")
+	for _, w := range widths {
+		b.WriteString(strings.Repeat("-", w+2))
+		b.WriteString("|")
+	}
+	b.WriteString("\n")
+	for _, row := range rows {
+		writeLine(row)
+	}
+
+	return b.Bytes()
+}
+
+// cellEscaper escapes the cells of the table. The backslashes are already
+// escaped by formatValue.
+var cellEscaper = strings.NewReplacer(`|`, `\|`, "\n", `\n`, "\r", `\r`)
+
+// escapeCell escapes the cell, and the trailing space, which is otherwise
+// trimmed with the padding when reading the table.
+func escapeCell(s string) string {
+	s = cellEscaper.Replace(s)
+	if strings.HasSuffix(s, " ") {
+		s = s[:len(s)-1] + `\ `
+	}
+
+	return s
+}
+
+// parseTable reads the table written by writeTable, without the separator.
+func parseTable(data []byte) [][]string {
+	var rows [][]string
+	for i, line := range strings.Split(string(data), "\n") {
+		// Skip the separator.
+		if i == 1 {
+			continue
+		}
+
+		rows = append(rows, readCells(strings.TrimSpace(line)))
+	}
+
+	return rows
+}
+
+// readCells reads the cells of the line, without the padding added by
+// writeTable, which is a space before the cell, and the spaces after.
+func readCells(line string) []string {
+	line = strings.TrimPrefix(line, "|")
+
+	var cells []string
+	// end is the end of the cell, without the trailing spaces, but with the
+	// escaped space, see escapeCell.
+	var start, end int
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+			end = i + 1
+		case '|':
+			cell := strings.TrimPrefix(line[start:max(start, end)], " ")
+			cells = append(cells, unescapeCell(cell))
+			start, end = i+1, i+1
+		case ' ':
+		default:
+			end = i + 1
+		}
+	}
+
+	return cells
+}
+
+// unescapeCell reverses escapeCell. The escapes of formatValue, e.g. `\\`
+// and `\N`, are kept.
+func unescapeCell(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case '|', ' ':
+			sb.WriteByte(s[i])
+		default:
+			sb.WriteByte(c)
+			sb.WriteByte(s[i])
+		}
+	}
+
+	return sb.String()
+}
+
+type comparer struct {
+	colors         bool
+	ignoreRowOrder bool
+}
+
+func (c *comparer) Compare(a, b any) error {
+	return c.compare(a.(*table), b.(*table))
+}
+
+func (c *comparer) compare(x, y *table) error {
+	comparer := diff.Text
+	if c.colors {
+		comparer = diff.ANSI
+	}
+
+	if err := comparer(x.Columns, y.Columns); err != nil {
+		return fmt.Errorf("Columns: %w", err)
+	}
+
+	if err := comparer(x.Header, y.Header); err != nil {
+		return fmt.Errorf("Header: %w", err)
+	}
+
+	var opts []cmp.Option
+	if c.ignoreRowOrder {
+		opts = append(opts, cmpopts.SortSlices(func(a, b []string) bool {
+			return slices.Compare(a, b) < 0
+		}))
+	}
+
+	if err := comparer(x.Rows, y.Rows, opts...); err != nil {
+		return fmt.Errorf("Rows: %w", err)
+	}
+
+	return nil
+}
+
+func appendNewLine(b []byte) []byte {
+	b = append(b, '\n')
+	b = append(b, '\n')
+	return b
+}
@@ -4,15 +4,16 @@ go 1.24.2
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/alextanhongpin/testdump/pkg/diff v0.0.0-20240617113601-585c236115fd
+	github.com/alextanhongpin/testdump/pkg/file v0.0.0-20240814172502-38533f751ca6
+	github.com/alextanhongpin/testdump/pkg/snapshot v0.0.0-20240814172502-38533f751ca6
 	github.com/alextanhongpin/testdump/yamldump v0.0.0-20250608043033-1b71f7f044e4
+	github.com/google/go-cmp v0.7.0
+	golang.org/x/tools v0.41.0
 )
 
 require (
-	github.com/alextanhongpin/testdump/pkg/diff v0.0.0-20240617113601-585c236115fd // indirect
-	github.com/alextanhongpin/testdump/pkg/file v0.0.0-20240814172502-38533f751ca6 // indirect
 	github.com/alextanhongpin/testdump/pkg/reviver v0.0.0-20240617113601-585c236115fd // indirect
-	github.com/alextanhongpin/testdump/pkg/snapshot v0.0.0-20240814172502-38533f751ca6 // indirect
-	github.com/google/go-cmp v0.6.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,117 @@
+package sqldump
+
+import (
+	"os"
+	"strconv"
+)
+
+const env = "TESTDUMP"
+
+type Option func(o *options)
+
+type options struct {
+	colors         bool
+	env            string
+	file           string
+	csv            bool
+	ignoreRowOrder bool
+	combined       bool
+	excludeColumns []string
+	maskColumns    map[string]string
+}
+
+func newOptions() *options {
+	return &options{
+		colors:      true,
+		env:         env,
+		maskColumns: make(map[string]string),
+	}
+}
+
+func (o *options) apply(opts ...Option) *options {
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) overwrite() bool {
+	t, _ := strconv.ParseBool(os.Getenv(o.env))
+	return t
+}
+
+func (o *options) encoder(table string) *encoder {
+	return &encoder{
+		csv:     o.csv,
+		table:   table,
+		exclude: o.excludeColumns,
+		masks:   o.maskColumns,
+	}
+}
+
+func (o *options) comparer() *comparer {
+	return &comparer{
+		colors:         o.colors,
+		ignoreRowOrder: o.ignoreRowOrder,
+	}
+}
+
+func Colors(colors bool) Option {
+	return func(o *options) {
+		o.colors = colors
+	}
+}
+
+func Env(env string) Option {
+	return func(o *options) {
+		o.env = env
+	}
+}
+
+func File(file string) Option {
+	return func(o *options) {
+		o.file = file
+	}
+}
+
+// CSV writes the rows as CSV instead of an aligned table.
+func CSV() Option {
+	return func(o *options) {
+		o.csv = true
+	}
+}
+
+// IgnoreRowOrder compares the rows regardless of the order, e.g. for queries
+// without ORDER BY.
+func IgnoreRowOrder() Option {
+	return func(o *options) {
+		o.ignoreRowOrder = true
+	}
+}
+
+// Combined writes the tables dumped by DumpTables into a single file,
+// instead of one file per table.
+func Combined() Option {
+	return func(o *options) {
+		o.combined = true
+	}
+}
+
+// ExcludeColumns removes the columns from the snapshot, e.g. created_at.
+// The column can be scoped to a table with DumpTables, e.g. users.created_at.
+func ExcludeColumns(columns ...string) Option {
+	return func(o *options) {
+		o.excludeColumns = append(o.excludeColumns, columns...)
+	}
+}
+
+// MaskColumns replaces the values of the columns with the mask in the
+// snapshot. NULL values are not masked.
+// The column can be scoped to a table with DumpTables, e.g. users.password.
+func MaskColumns(mask string, columns ...string) Option {
+	return func(o *options) {
+		for _, c := range columns {
+			o.maskColumns[c] = mask
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package sqldump
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+)
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Result is the rows with the columns in the order of the query.
+type Result struct {
+	Columns []Column
+	Rows    [][]any // The values as returned by the driver.
+}
+
+// Column is the column metadata from sql.ColumnType.
+// The metadata that is not supported by the driver is nil.
+type Column struct {
+	Name      string
+	Type      string // The database type name, e.g. VARCHAR.
+	Nullable  *bool
+	Length    *int64
+	Precision *int64
+	Scale     *int64
+}
+
+// String returns the column definition, e.g. `name VARCHAR(255) NOT NULL`.
+func (c Column) String() string {
+	s := c.Name
+	if c.Type != "" {
+		s += " " + c.Type
+	}
+
+	switch {
+	case c.Length != nil:
+		s += fmt.Sprintf("(%d)", *c.Length)
+	case c.Precision != nil && c.Scale != nil:
+		s += fmt.Sprintf("(%d,%d)", *c.Precision, *c.Scale)
+	}
+
+	if c.Nullable != nil {
+		if *c.Nullable {
+			s += " NULL"
+		} else {
+			s += " NOT NULL"
+		}
+	}
+
+	return s
+}
+
+// QueryResult is similar to Query, but keeps the column order and metadata.
+func QueryResult(ctx context.Context, db queryer, query string, args ...any) (*Result, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(types))
+	for i, ct := range types {
+		names[i] = ct.Name()
+	}
+
+	// Ensure unique column names.
+	names = uniqueColumns(names)
+
+	res := &Result{
+		Columns: make([]Column, len(types)),
+	}
+	for i, ct := range types {
+		res.Columns[i] = newColumn(names[i], ct)
+	}
+
+	for rows.Next() {
+		vals := make([]any, len(types))
+		ptrs := make([]any, len(types))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		res.Rows = append(res.Rows, vals)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func newColumn(name string, ct *sql.ColumnType) Column {
+	c := Column{
+		Name: name,
+		Type: ct.DatabaseTypeName(),
+	}
+	if nullable, ok := ct.Nullable(); ok {
+		c.Nullable = &nullable
+	}
+	// Unbounded types, e.g. TEXT, have the max length.
+	if n, ok := ct.Length(); ok && n != math.MaxInt64 {
+		c.Length = &n
+	}
+	if p, s, ok := ct.DecimalSize(); ok {
+		c.Precision, c.Scale = &p, &s
+	}
+
+	return c
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +33,194 @@ func TestDump(t *testing.T) {
 	yamldump.Dump(t, res, yamldump.IgnoreFields("created_at"))
 }
 
+func TestQueryResult(t *testing.T) {
+	query := func(t *testing.T, rows ...[]driver.Value) *sqldump.Result {
+		t.Helper()
+
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			db.Close()
+		})
+
+		mock.ExpectQuery("select(.+)").WillReturnRows(
+			mock.NewRowsWithColumnDefinition(
+				sqlmock.NewColumn("id").OfType("INT", int64(0)).Nullable(false),
+				sqlmock.NewColumn("name").OfType("VARCHAR", "").WithLength(255).Nullable(false),
+				sqlmock.NewColumn("balance").OfType("DECIMAL", "").WithPrecisionAndScale(10, 2).Nullable(true),
+				sqlmock.NewColumn("bio").OfType("TEXT", "").Nullable(true),
+			).AddRows(rows...),
+		)
+
+		res, err := sqldump.QueryResult(context.Background(), db, `select id, name, balance, bio from users`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res
+	}
+
+	alice := []driver.Value{int64(1), "alice", "10.50", []byte("likes | pipes\nand new lines")}
+	bob := []driver.Value{int64(2), "bob", nil, nil}
+	// The spaces, the string NULL and the backslashes are kept.
+	carol := []driver.Value{int64(3), " carol ", "0.00", "NULL"}
+	dave := []driver.Value{int64(4), "dave", nil, `\N C:\dave\`}
+
+	t.Run("table", func(t *testing.T) {
+		sqldump.Dump(t, query(t, alice, bob, carol, dave))
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		sqldump.Dump(t, query(t, alice, bob, carol, dave), sqldump.CSV())
+	})
+
+	t.Run("ignore row order", func(t *testing.T) {
+		sqldump.Dump(t, query(t, alice, bob), sqldump.IgnoreRowOrder())
+		sqldump.Dump(t, query(t, bob, alice), sqldump.IgnoreRowOrder())
+	})
+}
+
+func TestDumpTables(t *testing.T) {
+	mockTables := func(t *testing.T) *sql.DB {
+		t.Helper()
+
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			db.Close()
+		})
+
+		createdAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		mock.ExpectQuery(`SELECT version\(\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.2"))
+		mock.ExpectQuery(`SELECT current_schema\(\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"current_schema"}).AddRow("public"))
+
+		mock.ExpectQuery(`table_schema = 'public'\s+AND tc.table_name = 'users'`).
+			WillReturnRows(sqlmock.NewRows([]string{"column_name"}).AddRow("id"))
+		mock.ExpectQuery(`SELECT \* FROM "users" ORDER BY "id"`).WillReturnRows(
+			mock.NewRowsWithColumnDefinition(
+				sqlmock.NewColumn("id").OfType("INT", int64(0)).Nullable(false),
+				sqlmock.NewColumn("email").OfType("VARCHAR", "").WithLength(255).Nullable(false),
+				sqlmock.NewColumn("password").OfType("VARCHAR", "").WithLength(255).Nullable(true),
+				sqlmock.NewColumn("created_at").OfType("TIMESTAMP", time.Time{}).Nullable(false),
+			).
+				AddRow(int64(1), "alice@mail.com", "secret", createdAt).
+				AddRow(int64(2), "bob@mail.com", nil, createdAt),
+		)
+
+		// Without a primary key, the rows are sorted by the columns in the
+		// snapshot, not by the excluded created_at.
+		mock.ExpectQuery(`table_schema = 'public'\s+AND tc.table_name = 'tags'`).
+			WillReturnRows(sqlmock.NewRows([]string{"column_name"}))
+		mock.ExpectQuery(`SELECT \* FROM "tags"$`).WillReturnRows(
+			mock.NewRowsWithColumnDefinition(
+				sqlmock.NewColumn("created_at").OfType("TIMESTAMP", time.Time{}).Nullable(false),
+				sqlmock.NewColumn("name").OfType("TEXT", "").Nullable(false),
+			).
+				AddRow(createdAt, "zeta").
+				AddRow(createdAt.Add(time.Hour), "alpha"),
+		)
+
+		t.Cleanup(func() {
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+
+		return db
+	}
+
+	opts := []sqldump.Option{
+		sqldump.ExcludeColumns("created_at"),
+		sqldump.MaskColumns("[REDACTED]", "users.password"),
+	}
+
+	t.Run("per table", func(t *testing.T) {
+		sqldump.New(opts...).DumpTables(t, mockTables(t), "users", "tags")
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		sqldump.New(append(opts, sqldump.Combined())...).DumpTables(t, mockTables(t), "users", "tags")
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT version\(\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.36"))
+		mock.ExpectQuery(`SELECT DATABASE\(\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"database"}).AddRow("app"))
+
+		// The qualified name overrides the current database, and the
+		// reserved words are quoted.
+		mock.ExpectQuery(`table_schema = 'shop'\s+AND tc.table_name = 'order'`).
+			WillReturnRows(sqlmock.NewRows([]string{"column_name"}).AddRow("userId").AddRow("seq"))
+		mock.ExpectQuery("SELECT \\* FROM `shop`.`order` ORDER BY `userId`, `seq`").WillReturnRows(
+			sqlmock.NewRows([]string{"userId", "seq"}).AddRow(int64(1), int64(1)),
+		)
+
+		sqldump.DumpTables(t, db, "shop.order")
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("information_schema error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT version\(\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.2"))
+		mock.ExpectQuery(`SELECT current_schema\(\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"current_schema"}).AddRow("public"))
+		mock.ExpectQuery("information_schema").
+			WillReturnError(errors.New("permission denied"))
+
+		_, err = sqldump.QueryTables(context.Background(), db, "users")
+		if err == nil || !strings.Contains(err.Error(), "permission denied") {
+			t.Fatalf("want permission denied error, got %v", err)
+		}
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		// Without the information_schema, the rows are sorted by all the
+		// columns.
+		mock.ExpectQuery(`SELECT version\(\)`).
+			WillReturnError(errors.New("no such function: version"))
+		mock.ExpectQuery(`SELECT \* FROM "tags"$`).WillReturnRows(
+			sqlmock.NewRows([]string{"name"}).AddRow("zeta").AddRow("alpha"),
+		)
+
+		res, err := sqldump.QueryTables(context.Background(), db, "tags")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res[0].Rows[0][0]; got != "alpha" {
+			t.Fatalf("want alpha, got %v", got)
+		}
+	})
+}
+
 func newMockDB(t *testing.T, cols []string, vals []any) *sql.DB {
 	db, mock, err := sqlmock.New()
 	if err != nil {
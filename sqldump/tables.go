@@ -0,0 +1,357 @@
+package sqldump
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alextanhongpin/testdump/pkg/diff"
+	"github.com/alextanhongpin/testdump/pkg/file"
+	"github.com/alextanhongpin/testdump/pkg/snapshot"
+	"golang.org/x/tools/txtar"
+)
+
+// primaryKeyQuery returns the primary key columns of the table from the
+// information_schema, which is supported by Postgres and MySQL.
+const primaryKeyQuery = `SELECT kcu.column_name
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+ON kcu.constraint_name = tc.constraint_name
+AND kcu.table_schema = tc.table_schema
+AND kcu.table_name = tc.table_name
+WHERE tc.constraint_type = 'PRIMARY KEY'
+AND tc.table_schema = '%s'
+AND tc.table_name = '%s'
+ORDER BY kcu.ordinal_position`
+
+// TableResult is the rows of a table.
+type TableResult struct {
+	Name string
+	*Result
+
+	// unordered is true when the table has no primary key, so the rows in
+	// the snapshot are sorted by the columns that are not excluded.
+	unordered bool
+}
+
+// DumpTables writes the rows of each table, e.g. the database state after an
+// integration test, into one file per table, or into a single file with
+// Combined.
+// The rows are ordered by the primary key, or by the columns in the snapshot
+// when the primary key is not found.
+// The options, e.g. ExcludeColumns, are set with New, since the tables are
+// variadic.
+func DumpTables(t *testing.T, db queryer, tables ...string) {
+	d.DumpTables(t, db, tables...)
+}
+
+func (d *Dumper) DumpTables(t *testing.T, db queryer, tables ...string) {
+	t.Helper()
+
+	opt := newOptions().apply(d.opts...)
+
+	res, err := QueryTables(t.Context(), db, tables...)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if opt.combined {
+		name := opt.file
+		if name == "" {
+			name = "tables"
+		}
+
+		path := filepath.Join("testdata", t.Name(), fmt.Sprintf("%s.txt", name))
+		if err := dumpFile(path, opt, &tablesEncoder{opt: opt}, &tablesComparer{comparer: opt.comparer()}, res); err != nil {
+			t.Error(err)
+		}
+
+		return
+	}
+
+	for _, r := range res {
+		path := filepath.Join("testdata", t.Name(), fmt.Sprintf("%s.txt", r.Name))
+		enc := opt.encoder(r.Name)
+		enc.sortRows = r.unordered
+		if err := dumpFile(path, opt, enc, opt.comparer(), r.Result); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+type snapshotEncoder interface {
+	Marshal(any) ([]byte, error)
+	Unmarshal([]byte) (any, error)
+}
+
+type snapshotComparer interface {
+	Compare(a, b any) error
+}
+
+func dumpFile(path string, opt *options, enc snapshotEncoder, cmp snapshotComparer, v any) error {
+	f, err := file.New(path, opt.overwrite())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return snapshot.Snapshot(f, enc, cmp, v)
+}
+
+// QueryTables reads all the rows of the tables, ordered by the primary key.
+// The tables can be qualified with the schema, e.g. public.users, otherwise
+// the current schema is used.
+// The names are quoted, so they are case-sensitive, e.g. "userId".
+func QueryTables(ctx context.Context, db queryer, tables ...string) ([]TableResult, error) {
+	d, err := detectDialect(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]TableResult, len(tables))
+	for i, name := range tables {
+		pk, err := d.primaryKey(ctx, db, name)
+		if err != nil {
+			return nil, fmt.Errorf("sqldump: table %s: %w", name, err)
+		}
+
+		query := "SELECT * FROM " + d.quote(strings.Split(name, ".")...)
+		if len(pk) > 0 {
+			cols := make([]string, len(pk))
+			for i, col := range pk {
+				cols[i] = d.quote(col)
+			}
+			query += " ORDER BY " + strings.Join(cols, ", ")
+		}
+
+		r, err := QueryResult(ctx, db, query)
+		if err != nil {
+			return nil, fmt.Errorf("sqldump: table %s: %w", name, err)
+		}
+
+		if len(pk) == 0 {
+			sortRows(r)
+		}
+
+		res[i] = TableResult{Name: name, Result: r, unordered: len(pk) == 0}
+	}
+
+	return res, nil
+}
+
+// dialect is the identifier quote and the current schema of the database.
+type dialect struct {
+	identQuote string
+	schema     string
+
+	// informationSchema is false when the information_schema is not
+	// supported, e.g. SQLite.
+	informationSchema bool
+}
+
+// detectDialect detects the database from the version, without running
+// queries that fail on Postgres or MySQL, since a failed query aborts the
+// Postgres transaction.
+func detectDialect(ctx context.Context, db queryer) (*dialect, error) {
+	version, err := queryString(ctx, db, "SELECT version()")
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		// SQLite does not have version(), and the information_schema.
+		return &dialect{identQuote: `"`}, nil
+	}
+
+	if strings.Contains(version, "PostgreSQL") || strings.Contains(version, "CockroachDB") {
+		schema, err := queryString(ctx, db, "SELECT current_schema()")
+		if err != nil {
+			return nil, fmt.Errorf("sqldump: current schema: %w", err)
+		}
+
+		return &dialect{identQuote: `"`, schema: schema, informationSchema: true}, nil
+	}
+
+	// MySQL and MariaDB.
+	schema, err := queryString(ctx, db, "SELECT DATABASE()")
+	if err != nil {
+		return nil, fmt.Errorf("sqldump: current database: %w", err)
+	}
+
+	return &dialect{identQuote: "`", schema: schema, informationSchema: true}, nil
+}
+
+func queryString(ctx context.Context, db queryer, query string) (string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var s sql.NullString
+	if rows.Next() {
+		if err := rows.Scan(&s); err != nil {
+			return "", err
+		}
+	}
+
+	return s.String, rows.Err()
+}
+
+// quote quotes each part of the identifier, e.g. "public"."users".
+func (d *dialect) quote(parts ...string) string {
+	res := make([]string, len(parts))
+	for i, p := range parts {
+		res[i] = d.identQuote + strings.ReplaceAll(p, d.identQuote, d.identQuote+d.identQuote) + d.identQuote
+	}
+
+	return strings.Join(res, ".")
+}
+
+// primaryKey returns the primary key columns, or nil when the
+// information_schema is not supported, e.g. SQLite.
+func (d *dialect) primaryKey(ctx context.Context, db queryer, table string) ([]string, error) {
+	if !d.informationSchema {
+		return nil, nil
+	}
+
+	schema := d.schema
+	if s, name, ok := strings.Cut(table, "."); ok {
+		schema, table = s, name
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(primaryKeyQuery, quoteString(schema), quoteString(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+
+	return cols, rows.Err()
+}
+
+// quoteString escapes the string literal.
+func quoteString(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+// sortRows sorts the rows by all the columns, so that QueryTables returns
+// the same order on every run.
+// The snapshot is sorted again without the excluded columns, see
+// table.sortRows.
+func sortRows(r *Result) {
+	slices.SortStableFunc(r.Rows, func(a, b []any) int {
+		for i := range a {
+			if c := strings.Compare(formatValue(a[i]), formatValue(b[i])); c != 0 {
+				return c
+			}
+		}
+
+		return 0
+	})
+}
+
+// tablesEncoder writes the tables into a single file, with the sections
+// prefixed by the table name, e.g. users/columns and users/rows.
+type tablesEncoder struct {
+	opt *options
+}
+
+func (e *tablesEncoder) Marshal(v any) ([]byte, error) {
+	arc := new(txtar.Archive)
+	for _, r := range v.([]TableResult) {
+		t := newTable(r.Result, r.Name, e.opt.excludeColumns, e.opt.maskColumns)
+		if r.unordered {
+			t.sortRows()
+		}
+
+		files, err := writeSections(t, r.Name+"/", e.opt.csv)
+		if err != nil {
+			return nil, err
+		}
+		arc.Files = append(arc.Files, files...)
+	}
+
+	return txtar.Format(arc), nil
+}
+
+// Unmarshal reads the tables in the order of the file.
+func (e *tablesEncoder) Unmarshal(b []byte) (any, error) {
+	var names []string
+	tables := make(map[string]*table)
+	for _, f := range txtar.Parse(b).Files {
+		i := strings.LastIndex(f.Name, "/")
+		if i < 0 {
+			continue
+		}
+
+		name, section := f.Name[:i], f.Name[i+1:]
+		if _, ok := tables[name]; !ok {
+			names = append(names, name)
+			tables[name] = new(table)
+		}
+
+		if err := readSection(tables[name], section, f.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]namedTable, len(names))
+	for i, name := range names {
+		res[i] = namedTable{name: name, table: tables[name]}
+	}
+
+	return res, nil
+}
+
+type namedTable struct {
+	name  string
+	table *table
+}
+
+type tablesComparer struct {
+	comparer *comparer
+}
+
+func (c *tablesComparer) Compare(a, b any) error {
+	x, y := a.([]namedTable), b.([]namedTable)
+
+	names := func(ts []namedTable) []string {
+		res := make([]string, len(ts))
+		for i, t := range ts {
+			res[i] = t.name
+		}
+
+		return res
+	}
+
+	comparer := diff.Text
+	if c.comparer.colors {
+		comparer = diff.ANSI
+	}
+
+	if err := comparer(names(x), names(y)); err != nil {
+		return fmt.Errorf("Tables: %w", err)
+	}
+
+	for i := range x {
+		if err := c.comparer.compare(x[i].table, y[i].table); err != nil {
+			return fmt.Errorf("%s: %w", x[i].name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package sqlitedump
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alextanhongpin/testdump/pkg/diff"
+	"github.com/alextanhongpin/testdump/pkg/sqlrecorder"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rqlite/sql"
+)
+
+type comparer struct {
+	opts   []cmp.Option
+	colors bool
+	file   string
+}
+
+func (c *comparer) Compare(a, b any) error {
+	x := a.(*SQL)
+	y := b.(*SQL)
+
+	err := c.compare(x, y)
+	if err != nil {
+		if c.file != "" {
+			return fmt.Errorf("%s: %w", c.file, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *comparer) compare(snapshot, received *SQL) error {
+	comparer := diff.Text
+	if c.colors {
+		comparer = diff.ANSI
+	}
+
+	ok, err := CompareQuery(snapshot.Query, received.Query)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("Query: %w", comparer(snapshot.Query, received.Query))
+	}
+
+	lhs, err := toMap(snapshot.Args)
+	if err != nil {
+		return err
+	}
+	rhs, err := toMap(received.Args)
+	if err != nil {
+		return err
+	}
+
+	if err := comparer(lhs, rhs, c.opts...); err != nil {
+		return fmt.Errorf("Args: %w", err)
+	}
+
+	if err := comparer(snapshot.Plan, received.Plan); err != nil {
+		return fmt.Errorf("Plan: %w", err)
+	}
+
+	return nil
+}
+
+// SQL is the query and args of a single call.
+type SQL = sqlrecorder.SQL
+
+// CompareQuery checks if two queries are equal, ignoring variables.
+// The inline literals and the bind variables are treated the same, e.g.
+// `id = 1` matches `id = ?`, and `id IN (1, 2)` matches `id IN (?)`.
+func CompareQuery(a, b string) (bool, error) {
+	fa, err := fingerprintQuery(a)
+	if err != nil {
+		return false, err
+	}
+	fb, err := fingerprintQuery(b)
+	if err != nil {
+		return false, err
+	}
+
+	return fa == fb, nil
+}
+
+// fingerprintQuery returns the query with the literals and the bind
+// variables replaced with `?`, and the IN lists replaced with `(?)`.
+// The identifiers are lower-cased, since they are case-insensitive in SQLite.
+func fingerprintQuery(query string) (string, error) {
+	query, err := quoteIdents(query)
+	if err != nil {
+		return "", err
+	}
+
+	p := sql.NewParser(strings.NewReader(query))
+	stmt, err := p.ParseStatement()
+	if err != nil {
+		return "", err
+	}
+
+	// Only a single statement is recorded per call.
+	if _, err := p.ParseStatement(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("sqlitedump: multiple statements")
+		}
+
+		return "", err
+	}
+
+	n, err := sql.Walk(fingerprinter{}, stmt)
+	if err != nil {
+		return "", err
+	}
+
+	return n.String(), nil
+}
+
+// fingerprinter normalizes the nodes of the statement, see fingerprintQuery.
+type fingerprinter struct{}
+
+func (f fingerprinter) Visit(n sql.Node) (sql.Visitor, sql.Node, error) {
+	switch n := n.(type) {
+	case *sql.NumberLit, *sql.StringLit, *sql.BlobLit, *sql.BindExpr:
+		return nil, &sql.BindExpr{Name: "?"}, nil
+	case *sql.UnaryExpr:
+		// Negative numbers, e.g. `id = -1`.
+		if _, ok := n.X.(*sql.NumberLit); ok && (n.Op == sql.MINUS || n.Op == sql.PLUS) {
+			return nil, &sql.BindExpr{Name: "?"}, nil
+		}
+	case *sql.Ident:
+		n.Name = strings.ToLower(n.Name)
+	case *sql.WithClause:
+		// The common table expressions are not walked by sql.Walk.
+		for _, cte := range n.CTEs {
+			cte.TableName.Name = strings.ToLower(cte.TableName.Name)
+			for _, col := range cte.Columns {
+				col.Name = strings.ToLower(col.Name)
+			}
+
+			if _, err := sql.Walk(f, cte.Select); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return f, n, nil
+}
+
+func (f fingerprinter) VisitEnd(n sql.Node) (sql.Node, error) {
+	// Replace the IN list, e.g. `IN (?, ?, ?)`, with `IN (?)`.
+	b, ok := n.(*sql.BinaryExpr)
+	if !ok || (b.Op != sql.IN && b.Op != sql.NOTIN) {
+		return n, nil
+	}
+
+	if l, ok := b.Y.(*sql.ExprList); ok && isValues(l.Exprs) {
+		l.Exprs = l.Exprs[:1]
+	}
+
+	return n, nil
+}
+
+// isValues checks if the list only contains literals and bind variables,
+// which are already replaced by the fingerprinter.
+func isValues(exprs []sql.Expr) bool {
+	for _, e := range exprs {
+		if _, ok := e.(*sql.BindExpr); !ok {
+			return false
+		}
+	}
+
+	return len(exprs) > 0
+}
+
+// quoteIdents replaces the `name` and [name] quotes, which the parser does not
+// support, with "name".
+func quoteIdents(query string) (string, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	var last int
+	for _, t := range tokens {
+		if t.kind != tokenQuotedIdent || t.text[0] == '"' {
+			continue
+		}
+
+		sb.WriteString(query[last:t.start])
+		sb.WriteString(`"` + strings.ReplaceAll(unquote(t.text), `"`, `""`) + `"`)
+		last = t.end
+	}
+	sb.WriteString(query[last:])
+
+	return sb.String(), nil
+}
+
+// unquote removes the quotes of the identifier, e.g. "name", `name` or
+// [name].
+func unquote(s string) string {
+	if strings.HasPrefix(s, "[") {
+		return strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	}
+
+	q := s[:1]
+	return strings.ReplaceAll(s[1:len(s)-1], q+q, q)
+}
+
+// toMap converts the slice args into a map for better diff.
+// Each key is named `?n`, where `n` indicates the index of the arg in the
+// slice, or `:name` for sql.NamedArg.
+func toMap(s []any) (any, error) {
+	m := argsMap(s)
+
+	// Marshal/unmarshal to avoid type issues such as
+	// int/float.
+	// In JSON, there's only float.
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var a any
+	if err := json.Unmarshal(b, &a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
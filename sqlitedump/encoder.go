@@ -0,0 +1,232 @@
+package sqlitedump
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/txtar"
+)
+
+const (
+	querySection = "query"
+	argsSection  = "args"
+)
+
+type encoder struct {
+	marshalFns []func(*SQL) error
+}
+
+func (e *encoder) Marshal(v any) ([]byte, error) {
+	return Write(v.(*SQL), e.marshalFns...)
+}
+
+func (e *encoder) Unmarshal(b []byte) (a any, err error) {
+	return Read(b)
+}
+
+func Read(b []byte) (*SQL, error) {
+	d := new(SQL)
+
+	arc := txtar.Parse(b)
+	for _, f := range arc.Files {
+		name, data := f.Name, bytes.TrimSpace(f.Data)
+
+		switch name {
+		case querySection:
+			d.Query = string(data)
+		case argsSection:
+			args, err := readArgs(data)
+			if err != nil {
+				return nil, err
+			}
+			d.Args = args
+		case planSection:
+			d.Plan = string(data)
+		}
+	}
+
+	return d, nil
+}
+
+func Write(sql *SQL, transformers ...func(*SQL) error) ([]byte, error) {
+	q, err := normalize(sql.Query)
+	if err != nil {
+		return nil, err
+	}
+	sql.Query = q
+
+	for _, transform := range transformers {
+		if err := transform(sql); err != nil {
+			return nil, err
+		}
+	}
+
+	var a []byte
+	if len(sql.Args) > 0 {
+		a, err = json.MarshalIndent(argsMap(sql.Args), "", " ")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	arc := new(txtar.Archive)
+	// Query.
+	arc.Files = append(arc.Files, txtar.File{
+		Name: querySection,
+		Data: appendNewLine([]byte(sql.Query)),
+	})
+
+	// Args.
+	if len(a) != 0 {
+		arc.Files = append(arc.Files, txtar.File{
+			Name: argsSection,
+			Data: appendNewLine(a),
+		})
+	}
+
+	// Plan.
+	if sql.Plan != "" {
+		arc.Files = append(arc.Files, txtar.File{
+			Name: planSection,
+			Data: appendNewLine([]byte(sql.Plan)),
+		})
+	}
+
+	return txtar.Format(arc), nil
+}
+
+// argsMap names each arg `?n`, where `n` indicates the index of the arg in
+// the slice, or `:name` for sql.NamedArg.
+func argsMap(args []any) map[string]any {
+	m := make(map[string]any)
+	for i, v := range args {
+		key, v := argKey(i, v)
+		m[key] = v
+	}
+
+	return m
+}
+
+// argKey returns the key and the value of the i-th arg.
+func argKey(i int, v any) (string, any) {
+	if n, ok := v.(sql.NamedArg); ok {
+		return ":" + n.Name, n.Value
+	}
+
+	return fmt.Sprintf("?%d", i+1), v
+}
+
+// readArgs reads the args map written by Write back into a slice.
+// The named args are placed after the positional args, sorted by name.
+func readArgs(data []byte) ([]any, error) {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var args []any
+	var named []any
+	for _, k := range keys {
+		if name, ok := strings.CutPrefix(k, ":"); ok {
+			named = append(named, sql.Named(name, m[k]))
+			continue
+		}
+
+		i, err := strconv.Atoi(strings.TrimPrefix(k, "?")) // Index starts at 1
+		if err != nil {
+			return nil, err
+		}
+		if i > len(args) {
+			args = append(args, make([]any, i-len(args))...)
+		}
+		args[i-1] = m[k]
+	}
+
+	return append(args, named...), nil
+}
+
+// maskArgs replaces the values of the args with the keys with the mask.
+func maskArgs(args []any, mask string, keys ...string) []any {
+	res := make([]any, len(args))
+	for i, v := range args {
+		key, _ := argKey(i, v)
+		if slices.Contains(keys, key) {
+			if n, ok := v.(sql.NamedArg); ok {
+				v = sql.Named(n.Name, mask)
+			} else {
+				v = mask
+			}
+		}
+		res[i] = v
+	}
+
+	return res
+}
+
+func appendNewLine(b []byte) []byte {
+	b = append(b, '\n')
+	b = append(b, '\n')
+	return b
+}
+
+// normalize standardize the capitalization of the keywords and strip of new
+// lines and comments etc.
+func normalize(q string) (string, error) {
+	tokens, err := tokenize(q)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, t := range tokens {
+		// The trailing semicolon.
+		if t.text == ";" && i == len(tokens)-1 {
+			break
+		}
+
+		if i > 0 && space(tokens[i-1], t) {
+			sb.WriteByte(' ')
+		}
+
+		if t.kind == tokenKeyword && !qualified(tokens, i) {
+			sb.WriteString(strings.ToUpper(t.text))
+		} else {
+			sb.WriteString(t.text)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// space checks if the tokens are separated by a space, e.g. not after "(" or
+// before ",".
+func space(prev, next token) bool {
+	switch {
+	case prev.text == "(" || prev.text == ".":
+		return false
+	case next.text == ")" || next.text == "," || next.text == "." || next.text == ";":
+		return false
+	case next.text == "(":
+		// Function calls, e.g. count(*).
+		return prev.kind != tokenIdent && prev.kind != tokenQuotedIdent
+	default:
+		return true
+	}
+}
+
+// qualified checks if the keyword at i is used as a qualified identifier,
+// e.g. users.key.
+func qualified(tokens []token, i int) bool {
+	return (i > 0 && tokens[i-1].text == ".") || (i+1 < len(tokens) && tokens[i+1].text == ".")
+}
@@ -0,0 +1,90 @@
+package sqlitedump
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const planSection = "plan"
+
+type explainer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// explain runs `EXPLAIN QUERY PLAN` for SELECT queries, and returns the plan
+// as an indented tree, e.g.
+//
+//	SCAN o
+//	SEARCH u USING INTEGER PRIMARY KEY (rowid=?)
+//	USE TEMP B-TREE FOR ORDER BY
+//
+// Other queries are not explained, since EXPLAIN does not run them.
+func explain(ctx context.Context, db explainer, query string, args ...any) (string, error) {
+	ok, err := isSelect(query)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	rows, err := db.QueryContext(ctx, "EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		return "", fmt.Errorf("sqlitedump: explain: %w", err)
+	}
+	defer rows.Close()
+
+	// The depth of each node by id, where the parent is written before the
+	// children.
+	depth := make(map[int]int)
+
+	var sb strings.Builder
+	for rows.Next() {
+		var id, parent, notused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notused, &detail); err != nil {
+			return "", fmt.Errorf("sqlitedump: explain: %w", err)
+		}
+
+		if p, ok := depth[parent]; ok {
+			depth[id] = p + 1
+		}
+
+		sb.WriteString(strings.Repeat("  ", depth[id]))
+		sb.WriteString(detail)
+		sb.WriteString("\n")
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("sqlitedump: explain: %w", err)
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// isSelect checks if the query is a SELECT, including the common table
+// expressions, e.g. `WITH ... SELECT`.
+func isSelect(query string) (bool, error) {
+	tokens, err := tokenize(query)
+	if err != nil || len(tokens) == 0 {
+		return false, err
+	}
+
+	switch strings.ToUpper(tokens[0].text) {
+	case "SELECT", "VALUES":
+		return true, nil
+	case "WITH":
+		for _, t := range tokens {
+			if t.kind != tokenKeyword {
+				continue
+			}
+
+			switch strings.ToUpper(t.text) {
+			case "INSERT", "UPDATE", "DELETE", "REPLACE":
+				return false, nil
+			}
+		}
+
+		return true, nil
+	default:
+		return false, nil
+	}
+}
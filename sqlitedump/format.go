@@ -0,0 +1,30 @@
+package sqlitedump
+
+import (
+	"github.com/alextanhongpin/testdump/pkg/sqlformat"
+)
+
+// format normalizes the query and indents the clauses, using the tokens from
+// the SQLite tokenizer.
+func format(query string) (string, error) {
+	q, err := normalize(query)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := tokenize(q)
+	if err != nil {
+		return "", err
+	}
+
+	tokens := make([]sqlformat.Token, len(res))
+	for i, t := range res {
+		tokens[i] = sqlformat.Token{
+			Start:   t.start,
+			End:     t.end,
+			Keyword: t.kind == tokenKeyword && !qualified(res, i),
+		}
+	}
+
+	return sqlformat.Indent(q, tokens), nil
+}
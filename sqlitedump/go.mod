@@ -0,0 +1,28 @@
+module github.com/alextanhongpin/testdump/sqlitedump
+
+go 1.24.2
+
+require (
+	github.com/alextanhongpin/testdump/pkg/diff v0.0.0-20260202060108-045aa6c3cb8b
+	github.com/alextanhongpin/testdump/pkg/file v0.0.0-20260202060108-045aa6c3cb8b
+	github.com/alextanhongpin/testdump/pkg/snapshot v0.0.0-20260202060108-045aa6c3cb8b
+	github.com/alextanhongpin/testdump/pkg/sqlformat v0.0.0-20261019031221-48ff0785b84c
+	github.com/alextanhongpin/testdump/pkg/sqlrecorder v0.0.0-20261019043534-a6c70acb1f1f
+	github.com/google/go-cmp v0.7.0
+	github.com/rqlite/sql v0.0.0-20241111133259-a4122fabb196
+	golang.org/x/tools v0.41.0
+	modernc.org/sqlite v1.40.0
+)
+
+require (
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
+	golang.org/x/sys v0.40.0 // indirect
+	modernc.org/libc v1.66.10 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.11.0 // indirect
+)
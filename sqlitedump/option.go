@@ -0,0 +1,63 @@
+package sqlitedump
+
+import (
+	"context"
+
+	"github.com/alextanhongpin/testdump/pkg/sqlrecorder"
+)
+
+// Patterns for IgnoreArgPatterns.
+const (
+	UUIDPattern      = sqlrecorder.UUIDPattern
+	TimestampPattern = sqlrecorder.TimestampPattern
+)
+
+type Option = sqlrecorder.Option
+
+// The options shared by the SQL dumpers, see the sqlrecorder package for the
+// docs.
+var (
+	File              = sqlrecorder.File
+	Env               = sqlrecorder.Env
+	Colors            = sqlrecorder.Colors
+	IgnoreArgs        = sqlrecorder.IgnoreArgs
+	IgnoreArgPatterns = sqlrecorder.IgnoreArgPatterns
+	ForQuery          = sqlrecorder.ForQuery
+	AsTranscript      = sqlrecorder.AsTranscript
+	MaxQueries        = sqlrecorder.MaxQueries
+	DetectNPlusOne    = sqlrecorder.DetectNPlusOne
+	Summary           = sqlrecorder.Summary
+	Transformers      = sqlrecorder.Transformers
+)
+
+// MaskArgs replaces the values of the args with the mask in the snapshot,
+// e.g. `?1` or `:name` for sql.NamedArg.
+// Unlike IgnoreArgs, the args must still be present.
+func MaskArgs(mask string, args ...string) Option {
+	return Transformers(func(s *SQL) error {
+		s.Args = maskArgs(s.Args, mask, args...)
+		return nil
+	})
+}
+
+// Explain runs `EXPLAIN QUERY PLAN` on the db for each SELECT recorded by the
+// Recorder, and writes the plan to the plan section, so that plan
+// regressions, e.g. a missing index, shows up in the diff.
+// The db must see the same schema as the recorded queries, e.g. the tables
+// created in an uncommitted transaction are not visible to another
+// connection.
+func Explain(db explainer) Option {
+	return sqlrecorder.Explain(func(ctx context.Context, query string, args ...any) (string, error) {
+		return explain(ctx, db, query, args...)
+	})
+}
+
+// Prettify formats the query with each clause on a new line.
+var Prettify = Transformers(func(s *SQL) error {
+	q, err := format(s.Query)
+	if err != nil {
+		return err
+	}
+	s.Query = q
+	return nil
+})
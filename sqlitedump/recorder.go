@@ -0,0 +1,72 @@
+package sqlitedump
+
+import (
+	"testing"
+
+	"github.com/alextanhongpin/testdump/pkg/sqlrecorder"
+)
+
+// dialect is the SQLite specific part of the recorder.
+var dialect = &sqlrecorder.Dialect{
+	Name:         "sqlitedump",
+	Fingerprint:  fingerprintQuery,
+	CompareQuery: CompareQuery,
+	Normalize:    normalize,
+	IsSelect:     isSelect,
+	ArgsMap: func(args []any) (map[string]any, error) {
+		return argsMap(args), nil
+	},
+	ReadArgs: readArgs,
+}
+
+func init() {
+	// Set in init, since Dump refers to the dialect for the ForQuery options.
+	dialect.Dump = Dump
+}
+
+// Recorder logs the query and args.
+type Recorder = sqlrecorder.Recorder
+
+// DB records the calls made to the db.
+type DB = sqlrecorder.DB
+
+// Tx records the queries made in the transaction.
+type Tx = sqlrecorder.Tx
+
+// Transcript is the ordered list of calls made in a test.
+type Transcript = sqlrecorder.Transcript
+
+// Entry is a single call in the transcript.
+type Entry = sqlrecorder.Entry
+
+// QueryCount is the number of calls of the queries with the same
+// fingerprint.
+type QueryCount = sqlrecorder.QueryCount
+
+// NewRecorder returns a recorder that dumps the calls when the test
+// finishes.
+func NewRecorder(t *testing.T, opts ...Option) *Recorder {
+	return dialect.NewRecorder(t, opts...)
+}
+
+func NewDBRecorder(db sqlrecorder.DBTX, rec sqlrecorder.QueryRecorder) *DB {
+	return sqlrecorder.NewDBRecorder(db, rec)
+}
+
+// DumpTranscript writes the transcript into a single file.
+func DumpTranscript(t *testing.T, tr *Transcript, opts ...Option) {
+	t.Helper()
+
+	dialect.DumpTranscript(t, tr, opts...)
+}
+
+// WriteTranscript writes each entry as a section named after the method,
+// followed by the optional args and error sections.
+func WriteTranscript(tr *Transcript, transformers ...func(*SQL) error) ([]byte, error) {
+	return dialect.WriteTranscript(tr, transformers...)
+}
+
+// ReadTranscript reads the entries written by WriteTranscript.
+func ReadTranscript(b []byte) (*Transcript, error) {
+	return dialect.ReadTranscript(b)
+}
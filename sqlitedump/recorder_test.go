@@ -0,0 +1,183 @@
+package sqlitedump_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/testdump/sqlitedump"
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+create table users (
+	id integer primary key,
+	name text not null unique,
+	password text,
+	updated_at text
+);
+
+create table orders (
+	id integer primary key,
+	user_id integer not null references users (id),
+	created_at text
+);
+
+insert into users (id, name) values (1, 'Alice'), (2, 'Bob');
+`
+
+func TestRecorder(t *testing.T) {
+	rec := sqlitedump.NewRecorder(t).DB(newDB(t))
+	ctx := context.Background()
+
+	var id int
+	var name string
+	if err := rec.QueryRowContext(ctx, "select id, name from users where id = ?", 1).Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 || name != "Alice" {
+		t.Errorf("expected 1, Alice, got %d, %s", id, name)
+	}
+
+	if err := rec.QueryRowContext(ctx, "select id, name from users where id = ?", 2).Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+	if id != 2 || name != "Bob" {
+		t.Errorf("expected 2, Bob, got %d, %s", id, name)
+	}
+}
+
+func TestRecorderAssertions(t *testing.T) {
+	rec := sqlitedump.NewRecorder(t,
+		sqlitedump.MaxQueries(3),
+		sqlitedump.DetectNPlusOne(),
+		sqlitedump.Summary(),
+	).DB(newDB(t))
+	ctx := context.Background()
+
+	var id int
+	var name string
+	// Repeating the query with the same args is not flagged as N+1.
+	for range 2 {
+		if err := rec.QueryRowContext(ctx, "select id, name from users where id = ?", 1).Scan(&id, &name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := rec.QueryRowContext(ctx, "select id, name from users where name = ?", "Alice").Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorderSummary(t *testing.T) {
+	rec := sqlitedump.NewRecorder(t,
+		sqlitedump.DetectNPlusOne(),
+		sqlitedump.Summary(),
+		sqlitedump.File("users"),
+	).DB(newDB(t))
+	ctx := context.Background()
+
+	// Writes with different args are not N+1 queries.
+	for _, name := range []string{"Carol", "Dave"} {
+		if _, err := rec.ExecContext(ctx, "insert into users (name) values (?)", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The multi-line query is kept in the summary.
+	if _, err := rec.ExecContext(ctx, "insert into users (name, password) values (?, 'first line\nsecond line')", "Eve"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorderExplain(t *testing.T) {
+	db := newDB(t)
+
+	rec := sqlitedump.NewRecorder(t, sqlitedump.Explain(db)).DB(db)
+	ctx := context.Background()
+
+	rows, err := rec.QueryContext(ctx, "select u.id, u.name from users u join orders o on o.user_id = u.id where u.id = ? order by o.created_at", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		t.Error("want no rows")
+	}
+}
+
+func TestRecorderForQuery(t *testing.T) {
+	rec := sqlitedump.NewRecorder(t,
+		sqlitedump.AsTranscript(),
+		sqlitedump.ForQuery("insert into users (id, name, password) values (?, ?, ?)",
+			sqlitedump.IgnoreArgs("?1"),
+			sqlitedump.MaskArgs("[REDACTED]", "?3"),
+		),
+		// The id of the update is still compared.
+		sqlitedump.ForQuery("update users set password = ? where id = ?",
+			sqlitedump.MaskArgs("[REDACTED]", "?1"),
+		),
+	).DB(newDB(t))
+	ctx := context.Background()
+
+	if _, err := rec.ExecContext(ctx, "insert into users (id, name, password) values (?, ?, ?)", time.Now().UnixNano(), "Carol", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.ExecContext(ctx, "update users set password = ? where id = ?", "new secret", 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorderTranscript(t *testing.T) {
+	rec := sqlitedump.NewRecorder(t, sqlitedump.AsTranscript(), sqlitedump.Summary()).DB(newDB(t))
+	ctx := context.Background()
+
+	tx, err := rec.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The deferred rollback is not recorded, since the transaction is done.
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "insert into users (name) values (?)", "Carol"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "insert into users (name) values (?)", "Carol"); err == nil {
+		t.Fatal("want error, got nil")
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := rec.QueryRowContext(ctx, "select count(*) from users where name = ?", "Carol").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("want 0 users, got %d", n)
+	}
+}
+
+// newDB returns an in-memory database with the schema.
+func newDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	// Each connection has its own in-memory database.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
@@ -0,0 +1,60 @@
+package sqlitedump
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/alextanhongpin/testdump/pkg/file"
+	"github.com/alextanhongpin/testdump/pkg/snapshot"
+)
+
+var d *Dumper
+
+func init() {
+	d = New()
+}
+
+func Dump(t *testing.T, s *SQL, opts ...Option) {
+	d.Dump(t, s, opts...)
+}
+
+type Dumper struct {
+	opts []Option
+}
+
+func New(opts ...Option) *Dumper {
+	return &Dumper{
+		opts: opts,
+	}
+}
+
+func (d *Dumper) Dump(t *testing.T, s *SQL, opts ...Option) {
+	t.Helper()
+
+	opts = append(d.opts, opts...)
+	if err := dump(t, s, opts...); err != nil {
+		t.Error(err)
+	}
+}
+
+func dump(t *testing.T, s *SQL, opts ...Option) error {
+	opt := dialect.QueryOptions(s.Query, opts...)
+
+	path := filepath.Join("testdata", fmt.Sprintf("%s.sql", filepath.Join(t.Name(), opt.File)))
+	f, err := file.New(path, opt.Overwrite())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := &encoder{
+		marshalFns: opt.Transformers,
+	}
+	c := &comparer{
+		opts:   opt.CmpOpts,
+		colors: opt.Colors,
+	}
+
+	return snapshot.Snapshot(f, enc, c, s)
+}
@@ -0,0 +1,151 @@
+package sqlitedump_test
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/testdump/sqlitedump"
+)
+
+func TestDump(t *testing.T) {
+	dump := &sqlitedump.SQL{
+		Query: `select * from users where name = ? and age = ?`,
+		Args:  []any{"John", 13},
+	}
+
+	sqlitedump.Dump(t, dump)
+}
+
+func TestIgnoreFields(t *testing.T) {
+	dump := &sqlitedump.SQL{
+		Query: `select * from users where name = ? and created_at > ?`,
+		Args:  []any{"John", time.Now()},
+	}
+
+	sqlitedump.Dump(t, dump, sqlitedump.IgnoreArgs("?2"))
+}
+
+func TestNamedArgs(t *testing.T) {
+	dump := &sqlitedump.SQL{
+		Query: `update users set status = :status where id = ?`,
+		Args:  []any{1, sql.Named("status", "active")},
+	}
+
+	sqlitedump.Dump(t, dump)
+}
+
+func TestArgOptions(t *testing.T) {
+	dump := &sqlitedump.SQL{
+		Query: `insert into users (id, email, password, created_at) values (?, ?, ?, ?)`,
+		Args:  []any{newUUID(), "john.appleseed@mail.com", "secret", time.Now()},
+	}
+
+	sqlitedump.Dump(t, dump,
+		sqlitedump.MaskArgs("[REDACTED]", "?3"),
+		sqlitedump.IgnoreArgPatterns(sqlitedump.UUIDPattern, sqlitedump.TimestampPattern),
+	)
+}
+
+// newUUID returns a random UUID v7, e.g. for the generated primary keys.
+func newUUID() string {
+	return fmt.Sprintf("%08x-%04x-7%03x-8000-%012x", rand.Uint32(), rand.Uint32()&0xffff, rand.Uint32()&0xfff, rand.Uint64()&0xffffffffffff)
+}
+
+func TestTransformer(t *testing.T) {
+	dump := &sqlitedump.SQL{
+		Query: `select u.id, count(*) from users u join orders o on o.user_id = u.id where u.name = ? and u.id > ? group by u.id order by u.id`,
+		Args:  []any{"John", 1},
+	}
+
+	// Add pretty print sql for all dumps.
+	sd := sqlitedump.New(sqlitedump.Prettify)
+	sd.Dump(t, dump)
+}
+
+func TestCompareQuery(t *testing.T) {
+	base := `select name from users where name = ? and age > -1`
+	vars := []string{
+		base,
+		`SELECT "name" FROM [users] WHERE Name = ? AND age > ?;`,
+		`select name
+-- The comments are ignored.
+from users
+		where name = /* name */ ?1 and age > ?`,
+		// The inline literals are treated as bind variables.
+		`select name from users where name = 'John' and age > 0`,
+		`select name from users where name = :name and age > @age`,
+	}
+
+	for _, v := range vars {
+		ok, err := sqlitedump.CompareQuery(base, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("expected %s to match %s", base, v)
+		}
+	}
+}
+
+func TestCompareQueryList(t *testing.T) {
+	base := `select name from users where id in (?)`
+	vars := []string{
+		`select name from users where id in (?, ?, ?)`,
+		`select name from users where id in (1, 2)`,
+	}
+
+	for _, v := range vars {
+		ok, err := sqlitedump.CompareQuery(base, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("expected %s to match %s", base, v)
+		}
+	}
+
+	mismatch := []string{
+		`select name from users where id not in (?)`,
+		`select name from users where id = ?`,
+		`select name, age from users where id in (?)`,
+	}
+	for _, v := range mismatch {
+		ok, err := sqlitedump.CompareQuery(base, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Errorf("expected %s to not match %s", base, v)
+		}
+	}
+}
+
+func TestCompareQueryError(t *testing.T) {
+	queries := []string{
+		`select * from users where name = 'john`,
+		`select from where`,
+		`select 1; select 2`,
+	}
+
+	for _, q := range queries {
+		if _, err := sqlitedump.CompareQuery(q, `select 1`); err == nil {
+			t.Errorf("want error for %s, got nil", q)
+		}
+	}
+}
+
+func TestCompareQueryWith(t *testing.T) {
+	base := `with u as (select id from users where age > ?) select * from u where id in (?)`
+	v := `WITH U AS (SELECT id FROM users WHERE age > 18) SELECT * FROM u WHERE id IN (1, 2)`
+
+	ok, err := sqlitedump.CompareQuery(base, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("expected %s to match %s", base, v)
+	}
+}
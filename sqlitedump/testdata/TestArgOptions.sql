@@ -0,0 +1,11 @@
+-- query --
+INSERT INTO users(id, email, password, created_at) VALUES (?, ?, ?, ?)
+
+-- args --
+{
+ "?1": "97c8345b-0000-4000-8000-56f76d7c88ad",
+ "?2": "john.appleseed@mail.com",
+ "?3": "[REDACTED]",
+ "?4": "2026-10-19T03:36:38.972468842Z"
+}
+
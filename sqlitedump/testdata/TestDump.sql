@@ -0,0 +1,9 @@
+-- query --
+SELECT * FROM users WHERE name = ? AND age = ?
+
+-- args --
+{
+ "?1": "John",
+ "?2": 13
+}
+
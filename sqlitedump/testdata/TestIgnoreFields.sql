@@ -0,0 +1,9 @@
+-- query --
+SELECT * FROM users WHERE name = ? AND created_at > ?
+
+-- args --
+{
+ "?1": "John",
+ "?2": "2026-10-19T03:36:38.972218339Z"
+}
+
@@ -0,0 +1,9 @@
+-- query --
+UPDATE users SET status = :status WHERE id = ?
+
+-- args --
+{
+ ":status": "active",
+ "?1": 1
+}
+
@@ -0,0 +1,8 @@
+-- query --
+SELECT id, name FROM users WHERE id = ?
+
+-- args --
+{
+ "?1": 2
+}
+
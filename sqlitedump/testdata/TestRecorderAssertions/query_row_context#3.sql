@@ -0,0 +1,8 @@
+-- query --
+SELECT id, name FROM users WHERE name = ?
+
+-- args --
+{
+ "?1": "Alice"
+}
+
@@ -0,0 +1,4 @@
+-- summary --
+2 SELECT id, name FROM users WHERE id = ?
+1 SELECT id, name FROM users WHERE name = ?
+
@@ -0,0 +1,13 @@
+-- query --
+SELECT u.id, u.name FROM users u JOIN orders o ON o.user_id = u.id WHERE u.id = ? ORDER BY o.created_at
+
+-- args --
+{
+ "?1": 1
+}
+
+-- plan --
+SEARCH u USING INTEGER PRIMARY KEY (rowid=?)
+SCAN o
+USE TEMP B-TREE FOR ORDER BY
+
@@ -0,0 +1,19 @@
+-- exec_context --
+INSERT INTO users(id, name, password) VALUES (?, ?, ?)
+
+-- args --
+{
+ "?1": 1792380998966981751,
+ "?2": "Carol",
+ "?3": "[REDACTED]"
+}
+
+-- exec_context --
+UPDATE users SET password = ? WHERE id = ?
+
+-- args --
+{
+ "?1": "[REDACTED]",
+ "?2": 1
+}
+
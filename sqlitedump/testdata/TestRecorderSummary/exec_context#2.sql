@@ -0,0 +1,8 @@
+-- query --
+INSERT INTO users(name) VALUES (?)
+
+-- args --
+{
+ "?1": "Dave"
+}
+
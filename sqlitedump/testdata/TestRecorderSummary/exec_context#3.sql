@@ -0,0 +1,9 @@
+-- query --
+INSERT INTO users(name, password) VALUES (?, 'first line
+second line')
+
+-- args --
+{
+ "?1": "Eve"
+}
+
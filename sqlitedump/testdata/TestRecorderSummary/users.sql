@@ -0,0 +1,5 @@
+-- summary --
+2 INSERT INTO users(name) VALUES (?)
+1 INSERT INTO users(name, password) VALUES (?, 'first line
+	second line')
+
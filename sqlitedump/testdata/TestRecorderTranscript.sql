@@ -0,0 +1,33 @@
+-- begin --
+-- exec_context --
+INSERT INTO users(name) VALUES (?)
+
+-- args --
+{
+ "?1": "Carol"
+}
+
+-- exec_context --
+INSERT INTO users(name) VALUES (?)
+
+-- args --
+{
+ "?1": "Carol"
+}
+
+-- error --
+constraint failed: UNIQUE constraint failed: users.name (2067)
+
+-- rollback --
+-- query_row_context --
+SELECT count(*) FROM users WHERE name = ?
+
+-- args --
+{
+ "?1": "Carol"
+}
+
+-- summary --
+2 INSERT INTO users(name) VALUES (?)
+1 SELECT count(*) FROM users WHERE name = ?
+
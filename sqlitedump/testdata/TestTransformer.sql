@@ -0,0 +1,15 @@
+-- query --
+SELECT u.id, count(*)
+  FROM users u
+  JOIN orders o ON o.user_id = u.id
+  WHERE u.name = ?
+    AND u.id > ?
+  GROUP BY u.id
+  ORDER BY u.id
+
+-- args --
+{
+ "?1": "John",
+ "?2": 1
+}
+
@@ -0,0 +1,225 @@
+package sqlitedump
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenKeyword tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent // "name", `name` or [name].
+	tokenString
+	tokenNumber
+	tokenBlob  // x'0102'.
+	tokenParam // ?, ?1, :name, @name or $name.
+	tokenOperator
+)
+
+type token struct {
+	kind       tokenKind
+	text       string
+	start, end int
+}
+
+// keywords is the set of the SQLite keywords, see
+// https://sqlite.org/lang_keywords.html.
+var keywords = make(map[string]bool)
+
+func init() {
+	for _, k := range keywordList {
+		keywords[k] = true
+	}
+}
+
+var keywordList = []string{
+	"ABORT", "ACTION", "ADD", "AFTER", "ALL", "ALTER", "ALWAYS", "ANALYZE",
+	"AND", "AS", "ASC", "ATTACH", "AUTOINCREMENT", "BEFORE", "BEGIN",
+	"BETWEEN", "BY", "CASCADE", "CASE", "CAST", "CHECK", "COLLATE", "COLUMN",
+	"COMMIT", "CONFLICT", "CONSTRAINT", "CREATE", "CROSS", "CURRENT",
+	"CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP", "DATABASE", "DEFAULT",
+	"DEFERRABLE", "DEFERRED", "DELETE", "DESC", "DETACH", "DISTINCT", "DO",
+	"DROP", "EACH", "ELSE", "END", "ESCAPE", "EXCEPT", "EXCLUDE", "EXCLUSIVE",
+	"EXISTS", "EXPLAIN", "FAIL", "FILTER", "FIRST", "FOLLOWING", "FOR",
+	"FOREIGN", "FROM", "FULL", "GENERATED", "GLOB", "GROUP", "GROUPS",
+	"HAVING", "IF", "IGNORE", "IMMEDIATE", "IN", "INDEX", "INDEXED",
+	"INITIALLY", "INNER", "INSERT", "INSTEAD", "INTERSECT", "INTO", "IS",
+	"ISNULL", "JOIN", "KEY", "LAST", "LEFT", "LIKE", "LIMIT", "MATCH",
+	"MATERIALIZED", "NATURAL", "NO", "NOT", "NOTHING", "NOTNULL", "NULL",
+	"NULLS", "OF", "OFFSET", "ON", "OR", "ORDER", "OTHERS", "OUTER", "OVER",
+	"PARTITION", "PLAN", "PRAGMA", "PRECEDING", "PRIMARY", "QUERY", "RAISE",
+	"RANGE", "RECURSIVE", "REFERENCES", "REGEXP", "REINDEX", "RELEASE",
+	"RENAME", "REPLACE", "RESTRICT", "RETURNING", "RIGHT", "ROLLBACK", "ROW",
+	"ROWS", "SAVEPOINT", "SELECT", "SET", "TABLE", "TEMP", "TEMPORARY", "THEN",
+	"TIES", "TO", "TRANSACTION", "TRIGGER", "UNBOUNDED", "UNION", "UNIQUE",
+	"UPDATE", "USING", "VACUUM", "VALUES", "VIEW", "VIRTUAL", "WHEN", "WHERE",
+	"WINDOW", "WITH", "WITHOUT",
+}
+
+// operators are the multi-character operators, longest first.
+var operators = []string{"->>", "->", "||", "<=", ">=", "==", "!=", "<>", "<<", ">>"}
+
+// tokenize splits the query into tokens, following the rules of the SQLite
+// tokenizer. The comments and whitespaces are skipped.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+
+	isWord := func(c byte) bool {
+		return c == '_' || c == '$' || c >= 0x80 || isLetter(c) || isDigit(c)
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		start := i
+		kind := tokenOperator
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+			continue
+		case strings.HasPrefix(query[i:], "--"):
+			n := strings.IndexByte(query[i:], '\n')
+			if n < 0 {
+				n = len(query) - i
+			}
+			i += n
+			continue
+		case strings.HasPrefix(query[i:], "/*"):
+			// Unterminated comments are allowed.
+			n := strings.Index(query[i+2:], "*/")
+			if n < 0 {
+				i = len(query)
+			} else {
+				i += n + 4
+			}
+			continue
+		case (c == 'x' || c == 'X') && i+1 < len(query) && query[i+1] == '\'':
+			end, err := quoted(query, i+1, '\'')
+			if err != nil {
+				return nil, err
+			}
+			kind, i = tokenBlob, end
+		case c == '\'':
+			end, err := quoted(query, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			kind, i = tokenString, end
+		case c == '"' || c == '`':
+			end, err := quoted(query, i, c)
+			if err != nil {
+				return nil, err
+			}
+			kind, i = tokenQuotedIdent, end
+		case c == '[':
+			n := strings.IndexByte(query[i:], ']')
+			if n < 0 {
+				return nil, fmt.Errorf("sqlitedump: unterminated identifier at %d", i)
+			}
+			kind, i = tokenQuotedIdent, i+n+1
+		case c == '?':
+			i++
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+			kind = tokenParam
+		case (c == ':' || c == '@' || c == '$') && i+1 < len(query) && isWord(query[i+1]):
+			i++
+			for i < len(query) && isWord(query[i]) {
+				i++
+			}
+			kind = tokenParam
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			i = number(query, i)
+			kind = tokenNumber
+		case isWord(c):
+			for i < len(query) && isWord(query[i]) {
+				i++
+			}
+			kind = tokenIdent
+			if keywords[strings.ToUpper(query[start:i])] {
+				kind = tokenKeyword
+			}
+		default:
+			i++
+			for _, op := range operators {
+				if strings.HasPrefix(query[start:], op) {
+					i = start + len(op)
+					break
+				}
+			}
+			if r, _ := utf8.DecodeRuneInString(query[start:]); !strings.ContainsRune("+-*/%&|~<>=!(),.;", r) {
+				return nil, fmt.Errorf("sqlitedump: unrecognized token %q at %d", r, start)
+			}
+		}
+
+		tokens = append(tokens, token{
+			kind:  kind,
+			text:  query[start:i],
+			start: start,
+			end:   i,
+		})
+	}
+
+	return tokens, nil
+}
+
+// quoted returns the end of the quoted text starting at i, where the quote
+// is escaped by doubling it.
+func quoted(query string, i int, quote byte) (int, error) {
+	for j := i + 1; j < len(query); j++ {
+		if query[j] != quote {
+			continue
+		}
+		if j+1 < len(query) && query[j+1] == quote {
+			j++
+			continue
+		}
+
+		return j + 1, nil
+	}
+
+	return 0, fmt.Errorf("sqlitedump: unterminated %c at %d", quote, i)
+}
+
+// number returns the end of the number starting at i, e.g. 1, 1.5, .5, 1e10
+// or 0x1F.
+func number(query string, i int) int {
+	if strings.HasPrefix(query[i:], "0x") || strings.HasPrefix(query[i:], "0X") {
+		i += 2
+		for i < len(query) && (isDigit(query[i]) || strings.IndexByte("abcdefABCDEF_", query[i]) >= 0) {
+			i++
+		}
+
+		return i
+	}
+
+	for i < len(query) && (isDigit(query[i]) || query[i] == '_' || query[i] == '.') {
+		i++
+	}
+	if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+		j := i + 1
+		if j < len(query) && (query[j] == '+' || query[j] == '-') {
+			j++
+		}
+		if j < len(query) && isDigit(query[j]) {
+			i = j
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+		}
+	}
+
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}